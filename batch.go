@@ -0,0 +1,59 @@
+package xmlrpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Request represents a method call of a batch
+type Request struct {
+	MethodName string
+	Args       []interface{}
+}
+
+// Response represents the outcome of a method call of a batch, either Result or Err is set
+type Response struct {
+	Result *Result
+	Err    error
+}
+
+// CallBatch makes the calls concurrently with at most concurrency calls in progress at once, concurrency lower
+// than 1 means calls one by one. Responses are returned in the order of requests. Once the context is done,
+// no more calls are started and remaining responses contain the context error.
+func (c *Client) CallBatch(ctx context.Context, reqs []Request, concurrency int) []Response {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	responses := make([]Response, len(reqs))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, req := range reqs {
+		if ctx.Err() == nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			for j := i; j < len(reqs); j++ {
+				responses[j].Err = errors.Wrapf(ctx.Err(), "call '%s' not started", reqs[j].MethodName)
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, req Request) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			responses[i].Result, responses[i].Err = c.Call(ctx, req.MethodName, req.Args...)
+		}(i, req)
+	}
+
+	wg.Wait()
+	return responses
+}
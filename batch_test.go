@@ -0,0 +1,82 @@
+package xmlrpc
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var batchMethod = regexp.MustCompile(`<methodName>m(\d+)</methodName>`)
+
+func Test_Client_CallBatch(t *testing.T) {
+	var inFlight, maxInFlight int32
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		return newResponse(http.StatusOK, `<?xml version="1.0"?><methodResponse><params><param><value><int>`+
+			batchMethod.FindStringSubmatch(string(body))[1]+`</int></value></param></params></methodResponse>`), nil
+	})}
+
+	reqs := make([]Request, 20)
+	for i := range reqs {
+		reqs[i] = Request{MethodName: fmt.Sprintf("m%d", i), Args: []interface{}{i}}
+	}
+
+	responses := NewClient(endpointCorrect, httpClient).CallBatch(context.TODO(), reqs, 3)
+	if len(responses) != len(reqs) {
+		t.Fatal("Unexpected number of responses:", len(responses))
+	}
+	for i, res := range responses {
+		if res.Err != nil {
+			t.Fatal("Error:", res.Err)
+		}
+		if res.Result.ResultInt() != int64(i) {
+			t.Fatal("Responses are not in order of requests:", i, res.Result.ResultInt())
+		}
+	}
+	if atomic.LoadInt32(&maxInFlight) > 3 {
+		t.Fatal("Concurrency bound exceeded:", maxInFlight)
+	}
+}
+
+func Test_Client_CallBatch_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		cancel()
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+
+	reqs := []Request{{MethodName: "get"}, {MethodName: "get"}, {MethodName: "get"}}
+	responses := NewClient(endpointCorrect, httpClient).CallBatch(ctx, reqs, 1)
+	if responses[0].Err != nil || responses[0].Result.ResultInt() != 42 {
+		t.Fatal("First call failed:", responses[0].Err)
+	}
+	for _, res := range responses[1:] {
+		if errors.Cause(res.Err) != context.Canceled {
+			t.Fatal("Call isn't canceled:", res.Err)
+		}
+		if res.Result != nil {
+			t.Fatal("Canceled call returns result.")
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package xmlrpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// circuitBreaker stops requests after threshold consecutive failures for the cooldown, then a single probe request
+// is let through and the breaker is closed on its success or opened again on its failure
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether a request can be sent and whether it's the probe, the first request after the cooldown
+// becomes the probe
+func (b *circuitBreaker) allow() (allowed, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true, false
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false, false
+	}
+
+	b.probing = true
+	return true, true
+}
+
+// record updates the state by the outcome of an allowed request, requests canceled by the caller are not counted.
+// While the breaker is open, only the outcome of the probe changes the state, outcomes of requests sent before
+// the breaker was opened are ignored.
+func (b *circuitBreaker) record(ctx context.Context, err error, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	} else if b.failures >= b.threshold {
+		return
+	}
+
+	switch {
+	case err == nil || errors.Cause(err) == ErrNotModified:
+		b.failures = 0
+	case ctx.Err() != nil:
+	case probe:
+		b.openedAt = time.Now()
+	default:
+		b.failures++
+		if b.failures == b.threshold {
+			b.openedAt = time.Now()
+		}
+	}
+}
+
+// guard sends the request unless the circuit breaker is open and records the outcome
+func (c *Client) guard(ctx context.Context, request func() ([]byte, error)) ([]byte, error) {
+	if c.breaker == nil {
+		return request()
+	}
+	allowed, probe := c.breaker.allow()
+	if !allowed {
+		return nil, ErrCircuitOpen
+	}
+
+	res, err := request()
+	c.breaker.record(ctx, err, probe)
+	return res, err
+}
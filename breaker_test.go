@@ -0,0 +1,150 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_WithCircuitBreaker(t *testing.T) {
+	var calls int
+	failing := true
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if failing {
+			return newResponse(http.StatusBadGateway, ""), nil
+		}
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+	client := NewClient(endpointCorrect, httpClient, WithCircuitBreaker(2, 50*time.Millisecond))
+
+	call := func() error {
+		_, err := client.Call(context.TODO(), "get")
+		return err
+	}
+
+	// closed, failures are counted until the threshold
+	for i := 0; i < 2; i++ {
+		if err := call(); err == nil || errors.Cause(err) == ErrCircuitOpen {
+			t.Fatal("Unexpected error of failing call:", err)
+		}
+	}
+
+	// open, calls fail fast
+	if err := call(); errors.Cause(err) != ErrCircuitOpen {
+		t.Fatal("Error isn't ErrCircuitOpen:", err)
+	}
+	if calls != 2 {
+		t.Fatal("Request sent while circuit breaker is open, calls:", calls)
+	}
+
+	// half-open, failed probe opens the breaker again
+	time.Sleep(60 * time.Millisecond)
+	if err := call(); err == nil || errors.Cause(err) == ErrCircuitOpen {
+		t.Fatal("Probe isn't sent after cooldown:", err)
+	}
+	if err := call(); errors.Cause(err) != ErrCircuitOpen || calls != 3 {
+		t.Fatal("Circuit breaker isn't opened again after failed probe:", err)
+	}
+
+	// half-open, successful probe closes the breaker
+	time.Sleep(60 * time.Millisecond)
+	failing = false
+	for i := 0; i < 3; i++ {
+		if err := call(); err != nil {
+			t.Fatal("Error:", err)
+		}
+	}
+	if calls != 6 {
+		t.Fatal("Circuit breaker isn't closed after successful probe, calls:", calls)
+	}
+
+	// closed, a single failure doesn't reach the threshold
+	failing = true
+	if err := call(); err == nil || errors.Cause(err) == ErrCircuitOpen {
+		t.Fatal("Unexpected error of failing call:", err)
+	}
+	failing = false
+	if err := call(); err != nil {
+		t.Fatal("Error:", err)
+	}
+}
+
+func Test_WithCircuitBreaker_staleRequest(t *testing.T) {
+	var calls int32
+	started := make(chan struct{}, 3)
+	releaseStale := make(chan struct{})
+	releaseProbe := make(chan struct{})
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n := atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		switch n {
+		case 1:
+			<-releaseStale
+			return newResponse(http.StatusOK, responseInt), nil
+		case 3:
+			<-releaseProbe
+		}
+		return newResponse(http.StatusBadGateway, ""), nil
+	})}
+	client := NewClient(endpointCorrect, httpClient, WithCircuitBreaker(1, 20*time.Millisecond))
+
+	call := func() error {
+		_, err := client.Call(context.TODO(), "get")
+		return err
+	}
+	stale := make(chan error, 1)
+	probe := make(chan error, 1)
+
+	// closed, a slow request is sent and a failed one opens the breaker
+	go func() { stale <- call() }()
+	<-started
+	if err := call(); err == nil || errors.Cause(err) == ErrCircuitOpen {
+		t.Fatal("Unexpected error of failing call:", err)
+	}
+	<-started
+
+	// half-open, the probe is in flight when the stale request succeeds
+	time.Sleep(30 * time.Millisecond)
+	go func() { probe <- call() }()
+	<-started
+	close(releaseStale)
+	if err := <-stale; err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err := call(); errors.Cause(err) != ErrCircuitOpen {
+		t.Fatal("Stale request changed state of half-open circuit breaker:", err)
+	}
+
+	// the failed probe opens the breaker again
+	close(releaseProbe)
+	if err := <-probe; err == nil || errors.Cause(err) == ErrCircuitOpen {
+		t.Fatal("Unexpected error of failing probe:", err)
+	}
+	if err := call(); errors.Cause(err) != ErrCircuitOpen {
+		t.Fatal("Circuit breaker isn't opened again after failed probe:", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatal("Request sent while circuit breaker is open, calls:", n)
+	}
+}
+
+func Test_WithCircuitBreaker_faults(t *testing.T) {
+	client := faultClient(0x400, WithCircuitBreaker(1, time.Hour))
+	for i := 0; i < 3; i++ {
+		_, err := client.Call(context.TODO(), "one.vm.info", 42)
+		if _, ok := errors.Cause(err).(*Fault); !ok {
+			t.Fatal("Error isn't caused by fault:", err)
+		}
+	}
+}
+
+func Test_WithCircuitBreaker_disabled(t *testing.T) {
+	if client := NewClient(endpointCorrect, nil, WithCircuitBreaker(0, time.Second)); client.breaker != nil {
+		t.Fatal("Circuit breaker is enabled with zero threshold.")
+	}
+}
@@ -0,0 +1,83 @@
+package xmlrpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// resultCache holds results of calls of the configured methods for the TTL, keyed by a hash of the call
+type resultCache struct {
+	ttl     time.Duration
+	methods map[string]bool
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	results []*Result
+	expires time.Time
+}
+
+func newResultCache(ttl time.Duration, methods []string) *resultCache {
+	cache := &resultCache{ttl: ttl, methods: make(map[string]bool, len(methods)), entries: make(map[string]cacheEntry)}
+	for _, method := range methods {
+		cache.methods[method] = true
+	}
+
+	return cache
+}
+
+// get returns copies of cached results unless they expired
+func (rc *resultCache) get(key string) ([]*Result, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(rc.entries, key)
+		return nil, false
+	}
+
+	return cloneResults(entry.results), true
+}
+
+// put stores copies of the results and drops expired entries
+func (rc *resultCache) put(key string, results []*Result) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range rc.entries {
+		if now.After(entry.expires) {
+			delete(rc.entries, k)
+		}
+	}
+
+	rc.entries[key] = cacheEntry{results: cloneResults(results), expires: now.Add(rc.ttl)}
+}
+
+// cacheKey returns the hash of the scope, e.g. the method name, and the call payload, so large payloads aren't
+// kept as keys
+func cacheKey(scope string, payload []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(scope))
+	hash.Write([]byte{0})
+	hash.Write(payload)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func cloneResults(results []*Result) []*Result {
+	clones := make([]*Result, len(results))
+	for i, result := range results {
+		clones[i] = result.Clone()
+	}
+
+	return clones
+}
@@ -0,0 +1,111 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func countingClient(calls *int) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*calls++
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+}
+
+func Test_WithResultCache(t *testing.T) {
+	var calls int
+	client := NewClient(endpointCorrect, countingClient(&calls), WithResultCache(time.Hour, []string{"one.vm.info"}))
+
+	first, err := client.Call(context.TODO(), "one.vm.info", 42)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	first.resInt = 7
+
+	second, err := client.Call(context.TODO(), "one.vm.info", 42)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if calls != 1 {
+		t.Fatal("Cached call hits the transport, requests:", calls)
+	}
+	if second.ResultInt() != 42 {
+		t.Fatal("Cached result was modified through returned result.")
+	}
+
+	if _, err = client.Call(context.TODO(), "one.vm.info", 43); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if calls != 2 {
+		t.Fatal("Call with different arguments returns cached result, requests:", calls)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err = client.Call(context.TODO(), "one.vm.action", "terminate", 42); err != nil {
+			t.Fatal("Error:", err)
+		}
+	}
+	if calls != 4 {
+		t.Fatal("Result of method not configured for caching is cached, requests:", calls)
+	}
+}
+
+// countingStringer counts how many times it's encoded
+type countingStringer struct {
+	calls *int
+}
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "pancake"
+}
+
+func Test_WithResultCache_encodedOnce(t *testing.T) {
+	var calls, encodings int
+	client := NewClient(endpointCorrect, countingClient(&calls), WithStringers(),
+		WithResultCache(time.Hour, []string{"one.vm.info"}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Call(context.TODO(), "one.vm.info", countingStringer{&encodings}); err != nil {
+			t.Fatal("Error:", err)
+		}
+	}
+	if calls != 1 || encodings != 2 {
+		t.Fatal("Cached call isn't encoded once per call, requests:", calls, "encodings:", encodings)
+	}
+	for key := range client.cache.entries {
+		if len(key) != 64 {
+			t.Fatal("Cache key isn't hash of the call:", key)
+		}
+	}
+}
+
+func Test_WithResultCache_expired(t *testing.T) {
+	var calls int
+	client := NewClient(endpointCorrect, countingClient(&calls),
+		WithResultCache(20*time.Millisecond, []string{"one.vm.info"}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Call(context.TODO(), "one.vm.info", 42); err != nil {
+			t.Fatal("Error:", err)
+		}
+		time.Sleep(30 * time.Millisecond)
+	}
+	if calls != 2 {
+		t.Fatal("Expired result is returned, requests:", calls)
+	}
+}
+
+func Test_WithResultCache_fault(t *testing.T) {
+	client := faultClient(0x400, WithResultCache(time.Hour, []string{"one.vm.info"}))
+	for i := 0; i < 2; i++ {
+		if _, err := client.Call(context.TODO(), "one.vm.info", 42); err == nil {
+			t.Fatal("No error when server returns fault.")
+		}
+	}
+	if len(client.cache.entries) != 0 {
+		t.Fatal("Failed call is cached.")
+	}
+}
@@ -0,0 +1,45 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// charsetReader transcodes responses declaring a charset different from UTF-8. Only US-ASCII and ISO-8859-1
+// (Latin-1) are supported, as they can be converted without lookup tables and without depending
+// on golang.org/x/net/html/charset. Responses declaring any other charset, e.g. windows-1252 or ISO-8859-2,
+// fail with an unsupported charset error.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "l1":
+		return newLatin1Reader(input)
+	default:
+		return nil, errors.Errorf("unsupported charset '%s'", charset)
+	}
+}
+
+func newLatin1Reader(input io.Reader) (io.Reader, error) {
+	data, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// every ISO-8859-1 byte corresponds to the Unicode code point of the same value
+	buffer := bytes.NewBuffer(make([]byte, 0, len(data)*2))
+	for _, b := range data {
+		if b < utf8.RuneSelf {
+			buffer.WriteByte(b)
+		} else {
+			buffer.WriteRune(rune(b))
+		}
+	}
+
+	return buffer, nil
+}
@@ -3,31 +3,163 @@ package xmlrpc
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"math/big"
+	"net"
 	"net/http"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 )
 
+const defaultContentType = "text/xml"
+const defaultAccept = "text/xml"
+const unixScheme = "unix://"
+const unixEndpoint = "http://unix/"
+const structTagName = "xmlrpc"
+const defaultBase64StreamSize = 64 * 1024
+const defaultDurationUnit = time.Second
+
+// Transport sends XML-RPC request documents and receives response documents by other means than HTTP,
+// e.g. over a message broker
+type Transport interface {
+	RoundTrip(ctx context.Context, request []byte) ([]byte, error)
+}
+
 // Client is an XML-RPC client
 type Client struct {
-	client   *http.Client
+	settings
 	endpoint string
+	methods  methodCache
 }
 
-// NewClient is an XML-RPC client constructor
-func NewClient(endpoint string, client *http.Client) *Client {
-	return &Client{client, endpoint}
+// settings holds everything which can be adjusted by options, the encoder and parser are also used on their own
+// by Encoder and Decoder
+type settings struct {
+	client         *http.Client
+	resolver       func(ctx context.Context) (string, error)
+	contentType    string
+	accept         string
+	dialer         *net.Dialer
+	maxRequestSize int64
+	requestIDKey   interface{}
+	etagKey        interface{}
+	retry          retryPolicy
+	breaker        *circuitBreaker
+	flights        *flightGroup
+	cache          *resultCache
+	validator      func([]byte) error
+	faultMapper    func(code int, message string) error
+	transport      Transport
+	dryRun         func(methodName string, args []interface{}) (*Result, error)
+	streaming      bool
+	baseCtx        context.Context
+	reauth         func(ctx context.Context) error
+	shouldReauth   func(fault *Fault) bool
+	encoder        Encoder
+	parser         parser
+}
+
+// newSettings returns the defaults adjusted by the options, HTTP related options modify a copy of the client
+func newSettings(client *http.Client, opts []Option) settings {
+	s := settings{
+		client:       client,
+		contentType:  defaultContentType,
+		accept:       defaultAccept,
+		requestIDKey: RequestIDKey,
+		encoder:      newEncoder(),
+		parser:       newParser(),
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return s
 }
 
-func toValue(arg interface{}) (valueizable, error) {
+// NewClient is an XML-RPC client constructor, its behaviour can be adjusted by options. When client is nil,
+// http.DefaultClient is used. Endpoint can be also a Unix domain socket, e.g. 'unix:///var/run/one.sock',
+// then HTTP requests with path '/' are sent over the socket.
+func NewClient(endpoint string, client *http.Client, opts ...Option) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	c := &Client{
+		settings: newSettings(client, opts),
+		endpoint: endpoint,
+	}
+
+	if strings.HasPrefix(endpoint, unixScheme) {
+		c.useUnixSocket(strings.TrimPrefix(endpoint, unixScheme))
+	}
+
+	return c
+}
+
+// Reset restores settings of requests, their encoding and parsing of responses to defaults and invalidates
+// the cached methods, so the client can be reused, e.g. from a pool. The endpoint and the transport including
+// the HTTP client and its connections are kept. It must not be called concurrently with calls.
+func (c *Client) Reset() {
+	dialer, transport := c.dialer, c.transport
+	c.settings = newSettings(c.client, nil)
+	c.dialer, c.transport = dialer, transport
+	c.InvalidateMethods()
+}
+
+func (c *Client) useUnixSocket(path string) {
+	dialer := c.dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	c.endpoint = unixEndpoint
+	transportOption(func(transport *http.Transport) {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		}
+	})(&c.settings)
+}
+
+func (e *Encoder) toValue(arg interface{}) (valueizable, error) {
+	switch a := arg.(type) {
+	case Raw:
+		return newRaw(a)
+	case *big.Int:
+		if a == nil {
+			return nil, errors.Errorf("invalid type nil *big.Int")
+		}
+		return newBigInt(a, e.bigIntAsI8), nil
+	case time.Duration:
+		unit := e.durationUnit
+		if unit == 0 {
+			unit = defaultDurationUnit
+		}
+		return newInt(int64(a / unit)), nil
+	case Value:
+		return e.explicitValue(a)
+	case OrderedStruct:
+		return e.constructOrderedStruct(a)
+	case *Result:
+		return e.resultValue(a)
+	}
+
 	v := reflect.ValueOf(arg)
+	if s, ok := arg.(fmt.Stringer); ok && e.stringers && !(v.Kind() == reflect.Ptr && v.IsNil()) {
+		if _, isTime := arg.(time.Time); !isTime {
+			return newString(s.String()), nil
+		}
+	}
+
 	switch v.Kind() {
 	case reflect.Bool:
-		return newBoolean(v.Bool()), nil
+		return newBoolean(v.Bool(), e.booleanText), nil
 	case reflect.Int:
 		fallthrough
 	case reflect.Int8:
@@ -38,39 +170,192 @@ func toValue(arg interface{}) (valueizable, error) {
 		fallthrough
 	case reflect.Int64:
 		return newInt(v.Int()), nil
+	case reflect.Uint:
+		fallthrough
+	case reflect.Uint8:
+		fallthrough
+	case reflect.Uint16:
+		fallthrough
+	case reflect.Uint32:
+		fallthrough
+	case reflect.Uint64:
+		return newUint(v.Uint(), e.bigIntAsI8)
 	case reflect.Float32:
 		fallthrough
 	case reflect.Float64:
 		return newDouble(v.Float()), nil
+	case reflect.Complex64:
+		fallthrough
+	case reflect.Complex128:
+		return newComplex(v.Complex()), nil
 	case reflect.String:
 		return newString(v.String()), nil
 	case reflect.Struct:
-		if v.Type().PkgPath() != "time" || v.Type().Name() != "Time" {
-			return nil, errors.Errorf("invalid type %s", v.Kind().String())
+		if v.Type().PkgPath() == "time" && v.Type().Name() == "Time" {
+			return newDateTime(arg.(time.Time)), nil
 		}
 
-		return newDateTime(arg.(time.Time)), nil
+		return e.constructStructFromFields(v)
 	case reflect.Array:
 		fallthrough
 	case reflect.Slice:
-		if v.Type().Elem().Kind() == reflect.Uint8 {
-			return newBase64(arg.([]byte)), nil
+		// []int8 and other integer slices are always encoded as arrays
+		if v.Type().Elem().Kind() == reflect.Uint8 && !e.byteSliceAsArray {
+			return e.newBase64(byteSlice(v)), nil
 		}
 
-		return constructArray(v)
+		return e.constructArray(v)
 	case reflect.Map:
-		return constructStruct(v)
+		return e.constructStruct(v)
 	default:
 		return nil, errors.Errorf("invalid type %s", v.Kind().String())
 	}
 }
 
-func constructArray(v reflect.Value) (*array, error) {
+// byteSlice returns bytes of a byte array or of a slice of any type with byte elements, e.g. json.RawMessage
+func byteSlice(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+
+	data := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(data), v)
+	return data
+}
+
+func (e *Encoder) explicitValue(v Value) (valueizable, error) {
+	switch v.kind {
+	case KindInt:
+		return newInt(v.value.(int64)), nil
+	case KindString:
+		return newString(v.value.(string)), nil
+	case KindBool:
+		return newBoolean(v.value.(bool), e.booleanText), nil
+	case KindDouble:
+		return newDouble(v.value.(float64)), nil
+	case KindDateTime:
+		return newDateTime(v.value.(time.Time)), nil
+	case KindBase64:
+		return e.newBase64(v.value.([]byte)), nil
+	case KindArray:
+		return e.constructArray(reflect.ValueOf(v.value))
+	case KindStruct:
+		return e.constructStruct(reflect.ValueOf(v.value))
+	default:
+		return nil, errors.Errorf("invalid explicit value")
+	}
+}
+
+// resultValue encodes a result, struct members are encoded in the order of their names
+func (e *Encoder) resultValue(r *Result) (valueizable, error) {
+	if r == nil {
+		return nil, errors.Errorf("invalid type nil *Result")
+	}
+
+	switch r.kind {
+	case KindArray:
+		a := newArray()
+		for i, element := range r.resArray {
+			value, err := e.resultValue(element)
+			if err != nil {
+				return nil, errors.Wrapf(err, "element %d", i)
+			}
+			a.addValue(value)
+		}
+		return a, nil
+	case KindBase64:
+		return e.newBase64(r.resBase64), nil
+	case KindBool:
+		return newBoolean(r.resBoolean, e.booleanText), nil
+	case KindDateTime:
+		return newDateTime(r.resDateTime), nil
+	case KindDouble:
+		return newDouble(r.resDouble), nil
+	case KindInt:
+		return newInt(r.resInt), nil
+	case KindString:
+		return newString(r.resString), nil
+	case KindStruct:
+		names := make([]string, 0, len(r.resStruct))
+		for name := range r.resStruct {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		s := newStruct()
+		for _, name := range names {
+			value, err := e.resultValue(r.resStruct[name])
+			if err != nil {
+				return nil, errors.Wrapf(err, "member '%s'", name)
+			}
+			s.addMember(name, value)
+		}
+		return s, nil
+	default:
+		return nil, errors.Errorf("invalid result of kind %s", r.kind)
+	}
+}
+
+// newBase64 encodes large blobs later directly into the output buffer, only a placeholder is added to the payload
+func (e *Encoder) newBase64(data []byte) *scalar {
+	if e.base64StreamSize <= 0 || len(data) < e.base64StreamSize {
+		return newBase64(data)
+	}
+
+	e.blobs = append(e.blobs, data)
+	return newBase64Placeholder(len(e.blobs) - 1)
+}
+
+// writeBlobs replaces blob placeholders in the written document by base64 encoded blobs, blobs are encoded
+// directly into the output buffer without intermediate strings
+func (e *Encoder) writeBlobs(document []byte) (*bytes.Buffer, error) {
+	size := len(document)
+	for _, blob := range e.blobs {
+		size += base64.StdEncoding.EncodedLen(len(blob))
+	}
+
+	buffer := bytes.NewBuffer(make([]byte, 0, size))
+	if err := e.writeBlobsTo(buffer, document); err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+// writeBlobsTo writes the document to w with blob placeholders replaced by base64 encoded blobs
+func (e *Encoder) writeBlobsTo(w io.Writer, document []byte) error {
+	for i, blob := range e.blobs {
+		placeholder := []byte(blobPlaceholder(i))
+		index := bytes.Index(document, placeholder)
+		if index < 0 {
+			return errors.Errorf("placeholder of blob %d not found", i)
+		}
+
+		if _, err := w.Write(document[:index]); err != nil {
+			return errors.Wrap(err, "write failed")
+		}
+		blobEncoder := base64.NewEncoder(base64.StdEncoding, w)
+		if _, err := blobEncoder.Write(blob); err != nil {
+			return errors.Wrap(err, "blob encoding failed")
+		}
+		if err := blobEncoder.Close(); err != nil {
+			return errors.Wrap(err, "blob encoding failed")
+		}
+		document = document[index+len(placeholder):]
+	}
+	if _, err := w.Write(document); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+
+	return nil
+}
+
+func (e *Encoder) constructArray(v reflect.Value) (*array, error) {
 	array := newArray()
 	for i := 0; i < v.Len(); i++ {
-		value, err := toValue(v.Index(i).Interface())
+		value, err := e.toValue(v.Index(i).Interface())
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrapf(err, "element %d", i)
 		}
 		array.addValue(value)
 	}
@@ -78,17 +363,17 @@ func constructArray(v reflect.Value) (*array, error) {
 	return array, nil
 }
 
-func constructStruct(v reflect.Value) (*structure, error) {
+func (e *Encoder) constructStruct(v reflect.Value) (*structure, error) {
 	s := newStruct()
 	for _, k := range v.MapKeys() {
 		if k.Kind() != reflect.String {
-			return nil, errors.Errorf("invalid type %s", v.Kind().String())
+			return nil, errors.Errorf("invalid type %s of member name", k.Kind().String())
 		}
 
 		key := k.String()
-		value, err := toValue(v.MapIndex(reflect.ValueOf(key)).Interface())
+		value, err := e.toValue(v.MapIndex(k).Interface())
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrapf(err, "member '%s'", key)
 		}
 		s.addMember(key, value)
 	}
@@ -96,44 +381,130 @@ func constructStruct(v reflect.Value) (*structure, error) {
 	return s, nil
 }
 
-func (c *Client) preparePayload(methodName string, args ...interface{}) (*bytes.Buffer, error) {
-	payload := newPayload(methodName)
-	for _, arg := range args {
-		value, err := toValue(arg)
+func (e *Encoder) constructOrderedStruct(members OrderedStruct) (*structure, error) {
+	s := newStruct()
+	for _, member := range members {
+		value, err := e.toValue(member.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "member '%s'", member.Name)
+		}
+		s.addMember(member.Name, value)
+	}
+
+	return s, nil
+}
+
+// constructStructFromFields encodes exported fields of a Go struct as struct members, member names can be changed
+// by the 'xmlrpc' field tag and fields tagged with "-" are skipped
+func (e *Encoder) constructStructFromFields(v reflect.Value) (*structure, error) {
+	s := newStruct()
+	members := 0
+	for i := 0; i < v.NumField(); i++ {
+		name, ok := memberName(v.Type().Field(i))
+		if !ok {
+			continue
+		}
+
+		value, err := e.toValue(v.Field(i).Interface())
 		if err != nil {
-			return nil, errors.Wrap(err, "method arguments parsing failed")
+			return nil, errors.Wrapf(err, "member '%s'", name)
 		}
-		payload.addParam(value)
+		s.addMember(name, value)
+		members++
 	}
 
-	buffer := new(bytes.Buffer)
-	if _, err := payload.WriteTo(buffer); err != nil {
-		return nil, errors.Wrap(err, "write to buffer failed")
+	if members == 0 {
+		return nil, errors.Errorf("invalid type %s", v.Kind().String())
 	}
 
-	return buffer, nil
+	return s, nil
+}
+
+// memberName returns the struct member name of a field, the boolean is false for unexported fields
+// and fields skipped by 'xmlrpc:"-"' tag
+func memberName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+
+	name := field.Name
+	if tag, ok := field.Tag.Lookup(structTagName); ok {
+		if tag == "-" {
+			return "", false
+		}
+		if tag != "" {
+			name = tag
+		}
+	}
+
+	return name, true
+}
+
+func (c *Client) preparePayload(methodName string, args ...interface{}) (*bytes.Buffer, error) {
+	encoder := c.encoder
+	return encoder.encodeCall(methodName, args...)
+}
+
+// callContext returns the base context instead of an empty per-call context, i.e. Background or TODO
+func (c *Client) callContext(ctx context.Context) context.Context {
+	if c.baseCtx != nil && (ctx == context.Background() || ctx == context.TODO()) {
+		return c.baseCtx
+	}
+
+	return ctx
+}
+
+// roundTrip sends the payload by the custom transport if it's set, by HTTP otherwise
+func (c *Client) roundTrip(ctx context.Context, payload []byte) ([]byte, error) {
+	if c.transport != nil {
+		return c.transport.RoundTrip(ctx, payload)
+	}
+
+	return c.makeRequest(ctx, bytes.NewReader(payload))
 }
 
 func (c *Client) makeRequest(ctx context.Context, content io.Reader) ([]byte, error) {
-	req, err := http.NewRequest("POST", c.endpoint, content)
+	endpoint := c.endpoint
+	if c.resolver != nil {
+		var err error
+		if endpoint, err = c.resolver(ctx); err != nil {
+			return nil, errors.Wrap(err, "endpoint resolution failed")
+		}
+	}
+
+	req, err := http.NewRequest("POST", endpoint, content)
 	if err != nil {
 		return nil, errors.Wrap(err, "request preparation failed")
 	}
 
-	req.Header.Set("Content-Type", "text/xml")
+	req.Header.Set("Content-Type", c.contentType)
+	if c.accept != "" {
+		req.Header.Set("Accept", c.accept)
+	}
+	if c.etagKey != nil {
+		if etag, ok := ctx.Value(c.etagKey).(string); ok && etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+	}
+	prefix := c.logPrefix(ctx)
+	logDebug("%ssending request to '%s'", prefix, endpoint)
 	res, err := c.client.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, errors.Wrap(err, "connection error")
 	}
 
+	logDebug("%sreceived response with code %d", prefix, res.StatusCode)
 	defer func() {
 		if err = res.Body.Close(); err != nil {
-			logError(errors.Wrap(err, "response body closing failed").Error())
+			logError("%s%s", prefix, errors.Wrap(err, "response body closing failed"))
 		}
 	}()
 
+	if res.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
 	if res.StatusCode/100 != 2 {
-		return nil, errors.Errorf("response error: code %d", res.StatusCode)
+		return nil, newStatusError(res)
 	}
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
@@ -143,17 +514,225 @@ func (c *Client) makeRequest(ctx context.Context, content io.Reader) ([]byte, er
 	return body, nil
 }
 
-// Call represents an XML-RPC method call
+// Call represents an XML-RPC method call, returned errors are wrapped with the method name and the number
+// of arguments only, but the wrapped errors, e.g. fault messages of the server, may contain argument values
 func (c *Client) Call(ctx context.Context, methodName string, args ...interface{}) (*Result, error) {
+	result, err := c.call(c.callContext(ctx), methodName, args...)
+	if err != nil {
+		return nil, &callError{errors.Wrapf(err, "call '%s' with %d arguments failed", methodName, len(args))}
+	}
+
+	return result, nil
+}
+
+func (c *Client) call(ctx context.Context, methodName string, args ...interface{}) (*Result, error) {
+	results, err := c.callMulti(ctx, methodName, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+// CallMulti represents an XML-RPC method call whose response contains multiple params,
+// a result is returned for each param in the order of the response
+func (c *Client) CallMulti(ctx context.Context, methodName string, args ...interface{}) ([]*Result, error) {
+	results, err := c.callMulti(c.callContext(ctx), methodName, args...)
+	if err != nil {
+		return nil, &callError{errors.Wrapf(err, "call '%s' with %d arguments failed", methodName, len(args))}
+	}
+
+	return results, nil
+}
+
+func (c *Client) callMulti(ctx context.Context, methodName string, args ...interface{}) ([]*Result, error) {
+	if c.dryRun != nil {
+		result, err := c.callDry(methodName, args...)
+		if err != nil {
+			return nil, err
+		}
+		return []*Result{result}, nil
+	}
+	if c.cache != nil && c.cache.methods[methodName] {
+		return c.callCached(ctx, methodName, args...)
+	}
+
+	return c.callRemote(ctx, nil, methodName, args...)
+}
+
+// callCached returns results cached for the same method name and arguments, or makes the call and caches them
+func (c *Client) callCached(ctx context.Context, methodName string, args ...interface{}) ([]*Result, error) {
+	payload, err := c.preparePayload(methodName, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "payload preparation failed")
+	}
+
+	key := cacheKey(methodName, payload.Bytes())
+	if results, ok := c.cache.get(key); ok {
+		return results, nil
+	}
+
+	results, err := c.callRemote(ctx, payload, methodName, args...)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.put(key, results)
+
+	return results, nil
+}
+
+// callRemote sends the call, repeats it after reauthentication if needed and parses the results, the payload
+// is encoded from the arguments unless it's already prepared
+func (c *Client) callRemote(ctx context.Context, payload *bytes.Buffer, methodName string,
+	args ...interface{}) ([]*Result, error) {
+	results, err := c.exchange(ctx, payload, methodName, args...)
+	if err != nil && c.needsReauth(err) {
+		if err = c.reauth(ctx); err != nil {
+			return nil, errors.Wrap(err, "reauthentication failed")
+		}
+		results, err = c.exchange(ctx, payload, methodName, args...)
+	}
+	if err != nil {
+		return nil, c.mapFault(err)
+	}
+
+	return results, nil
+}
+
+// exchange sends the method call and parses results of the response
+func (c *Client) exchange(ctx context.Context, payload *bytes.Buffer, methodName string,
+	args ...interface{}) ([]*Result, error) {
+	res, err := c.send(ctx, payload, methodName, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := c.parser
+	return parser.parseResults(res)
+}
+
+// needsReauth reports whether the call failed with a fault after which credentials should be refreshed
+func (c *Client) needsReauth(err error) bool {
+	fault, ok := errors.Cause(err).(*Fault)
+	return ok && c.reauth != nil && c.shouldReauth(fault)
+}
+
+// callDry checks the arguments can be encoded and returns the result of the dry run hook instead of sending the call
+func (c *Client) callDry(methodName string, args ...interface{}) (*Result, error) {
+	if _, err := c.preparePayload(methodName, args...); err != nil {
+		return nil, errors.Wrap(err, "payload preparation failed")
+	}
+
+	result, err := c.dryRun(methodName, args)
+	if err != nil {
+		return nil, c.mapFault(err)
+	}
+
+	return result, nil
+}
+
+// send prepares the payload of the method call unless it's already prepared, sends it and returns the validated
+// response body. A prepared payload is always sent buffered.
+func (c *Client) send(ctx context.Context, payload *bytes.Buffer, methodName string,
+	args ...interface{}) ([]byte, error) {
+	var res []byte
+	var err error
+	switch {
+	case payload != nil:
+		res, err = c.sendPayload(ctx, payload)
+	case c.streaming && c.transport == nil:
+		res, err = c.sendStreamed(ctx, methodName, args...)
+	default:
+		res, err = c.sendBuffered(ctx, methodName, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.validator != nil {
+		if err = c.validator(res); err != nil {
+			return nil, errors.Wrap(err, "response validation failed")
+		}
+	}
+
+	return res, nil
+}
+
+func (c *Client) sendBuffered(ctx context.Context, methodName string, args ...interface{}) ([]byte, error) {
 	content, err := c.preparePayload(methodName, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "payload preparation failed")
 	}
 
-	res, err := c.makeRequest(ctx, content)
+	return c.sendPayload(ctx, content)
+}
+
+func (c *Client) sendPayload(ctx context.Context, content *bytes.Buffer) ([]byte, error) {
+	if c.maxRequestSize > 0 && int64(content.Len()) > c.maxRequestSize {
+		return nil, errors.Errorf("payload size %d exceeds maximum request size %d", content.Len(), c.maxRequestSize)
+	}
+
+	request := func() ([]byte, error) {
+		return c.guard(ctx, func() ([]byte, error) {
+			return c.makeRequestWithRetry(ctx, content.Bytes())
+		})
+	}
+
+	var res []byte
+	var err error
+	if c.flights != nil && c.resolver == nil {
+		res, err = c.flights.do(ctx, c.flightKey(ctx, content.Bytes()), request)
+	} else {
+		res, err = request()
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "request failed")
 	}
 
-	return parseResult(res)
+	return res, nil
+}
+
+// mapFault translates a fault to an error by the fault mapper, other errors and faults for which the mapper
+// returns nil are returned unchanged
+func (c *Client) mapFault(err error) error {
+	fault, ok := errors.Cause(err).(*Fault)
+	if !ok || c.faultMapper == nil {
+		return err
+	}
+
+	if mapped := c.faultMapper(fault.Code, fault.Message); mapped != nil {
+		return mapped
+	}
+
+	return err
+}
+
+// CallNamed represents an XML-RPC method call with named arguments, which are sent as members of a single struct param
+func (c *Client) CallNamed(ctx context.Context, methodName string, params map[string]interface{}) (*Result, error) {
+	return c.Call(ctx, methodName, params)
+}
+
+// BuildPayload returns the XML document which would be sent as the body of the method call, without sending it
+func (c *Client) BuildPayload(methodName string, args ...interface{}) ([]byte, error) {
+	buffer, err := c.preparePayload(methodName, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "payload preparation failed")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// CallVoid represents an XML-RPC method call whose return value is not needed, only errors including faults are returned
+func (c *Client) CallVoid(ctx context.Context, methodName string, args ...interface{}) error {
+	_, err := c.Call(ctx, methodName, args...)
+	return err
+}
+
+// CallTimeout represents an XML-RPC method call which is canceled when it doesn't finish within the timeout,
+// the deadline is derived from the base context set by WithBaseContext
+func (c *Client) CallTimeout(timeout time.Duration, methodName string, args ...interface{}) (*Result, error) {
+	ctx, cancel := context.WithTimeout(c.callContext(context.Background()), timeout)
+	defer cancel()
+
+	return c.Call(ctx, methodName, args...)
 }
@@ -4,10 +4,17 @@ import (
 	"testing"
 
 	"context"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -21,6 +28,7 @@ const (
 	wrongMethodName = "records/wrong_method_name"
 	emptyMethodName = "records/empty_method_name"
 	wrongEndpoint   = "records/wrong_endpoint"
+	faultRecord     = "records/fault"
 )
 
 func Test_NewClient(t *testing.T) {
@@ -30,28 +38,72 @@ func Test_NewClient(t *testing.T) {
 	}
 }
 
+func Test_Client_Reset(t *testing.T) {
+	var headers http.Header
+	httpClient := headerRecordingClient(&headers)
+	client := NewClient(endpointCorrect, httpClient, WithContentType("application/xml"), WithMaxRequestSize(1),
+		WithRetry(3, time.Second), WithIndent("", "  "), WithTrimStrings(), WithStreamingRequests(),
+		WithBaseContext(context.Background()), WithCircuitBreaker(1, time.Second), WithAccept("application/xml"),
+		WithSingleFlight(), WithDialTimeout(time.Second), WithDryRun(func(methodName string, args []interface{}) (*Result, error) {
+			return nil, errors.New("dry run")
+		}))
+	client.methods.methods = map[string]bool{"get": true}
+
+	client.Reset()
+	defaults := NewClient(endpointCorrect, httpClient)
+	if client.contentType != defaults.contentType || client.maxRequestSize != 0 || client.retry != defaults.retry ||
+		client.dryRun != nil || client.streaming || client.baseCtx != nil || client.methods.methods != nil {
+		t.Fatal("Request settings aren't reset.")
+	}
+	if client.breaker != nil || client.flights != nil || client.accept != defaultAccept {
+		t.Fatal("Settings added by later options aren't reset.")
+	}
+	if client.dialer == nil {
+		t.Fatal("Dialer isn't kept.")
+	}
+	if client.encoder.indent != "" || client.parser.trimStrings {
+		t.Fatal("Encoding or parsing settings aren't reset.")
+	}
+	if client.client != httpClient || client.endpoint != endpointCorrect {
+		t.Fatal("HTTP client or endpoint isn't kept.")
+	}
+
+	if _, err := client.Call(context.TODO(), "get", "pancake"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if headers.Get("Content-Type") != defaultContentType {
+		t.Fatal("Unexpected Content-Type header after reset:", headers.Get("Content-Type"))
+	}
+}
+
 func MakeCallAndCreateRecord(t *testing.T, recorderName string, endpoint string, methodName string,
 	args ...interface{}) (*Result, error) {
+	return MakeCallWithOptionsAndCreateRecord(t, recorderName, endpoint, nil, methodName, args...)
+}
+
+func MakeCallWithOptionsAndCreateRecord(t *testing.T, recorderName string, endpoint string, opts []Option,
+	methodName string, args ...interface{}) (*Result, error) {
+	client, stop := CreateClientWithRecorder(t, recorderName, endpoint, opts...)
+	defer stop() // Make sure recorder is stopped once done with it
+
+	// Make call
+	return client.Call(context.TODO(), methodName, args...)
+}
+
+func CreateClientWithRecorder(t *testing.T, recorderName string, endpoint string, opts ...Option) (*Client, func()) {
 	// Start our recorder
 	r, err := recorder.New(recorderName)
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer r.Stop() // Make sure recorder is stopped once done with it
-
-	// Create an HTTP client and inject our transport
-	cl := &http.Client{
-		Transport: r, // Inject as transport!
-	}
 
-	// Create XML-RPC client and set HTTP client
-	client := NewClient(endpoint, cl)
+	// Create XML-RPC client and inject our transport
+	client := NewClient(endpoint, nil, append([]Option{WithTransport(r)}, opts...)...)
 	if client == nil {
 		t.Fatal("Unable to create xml-rpc client.")
 	}
 
-	// Make call
-	return client.Call(context.TODO(), methodName, args...)
+	return client, func() { r.Stop() }
 }
 
 func Test_Call_preparePayload_nilArgs(t *testing.T) {
@@ -108,11 +160,12 @@ func Test_Call_preparePayload_wrongMethodName(t *testing.T) {
 }
 
 func Test_Call_preparePayload_emptyMethodName(t *testing.T) {
+	// test expects fail before connection to the server
 	res, err := MakeCallAndCreateRecord(t, emptyMethodName, endpointCorrect, "")
 	if err == nil {
-		t.Error("No error when method name is empty.")
+		t.Fatal("No error when method name is empty.")
 	}
-	if !strings.Contains(err.Error(), "cannot parse XML RPC response") {
+	if errors.Cause(err) != ErrEmptyMethod {
 		t.Fatal("Unexpected error:", err)
 	}
 	if res != nil {
@@ -160,3 +213,275 @@ func Test_Call_makeRequest_emptyEndpoint(t *testing.T) {
 		t.Fatal("Method Call returns result when endpoint is empty.")
 	}
 }
+
+func Test_CallVoid(t *testing.T) {
+	client, stop := CreateClientWithRecorder(t, argsBoolt, endpointCorrect)
+	defer stop()
+
+	if err := client.CallVoid(context.TODO(), "get", true); err != nil {
+		t.Fatal("Error:", err)
+	}
+}
+
+func Test_CallVoid_fault(t *testing.T) {
+	client, stop := CreateClientWithRecorder(t, faultRecord, endpointCorrect)
+	defer stop()
+
+	err := client.CallVoid(context.TODO(), "one.vm.action", "terminate", 42)
+	if err == nil {
+		t.Fatal("No error when server returns fault.")
+	}
+	if !strings.Contains(err.Error(), "XML RPC error: 1024: [one.vm.action] Error getting virtual machine [42].") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if fault, ok := errors.Cause(err).(*Fault); !ok || fault.Code != 1024 {
+		t.Fatal("Error isn't caused by fault:", err)
+	}
+}
+
+func Test_CallTimeout(t *testing.T) {
+	delay := time.Second
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		select {
+		case <-time.After(delay):
+			return newResponse(http.StatusOK, responseInt), nil
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	})}
+	client := NewClient(endpointCorrect, httpClient)
+
+	start := time.Now()
+	res, err := client.CallTimeout(50*time.Millisecond, "get")
+	if err == nil {
+		t.Fatal("No error when call times out.")
+	}
+	if !strings.Contains(err.Error(), "context deadline exceeded") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when call times out.")
+	}
+	if elapsed := time.Since(start); elapsed >= delay {
+		t.Fatal("Call isn't canceled by timeout, elapsed:", elapsed)
+	}
+
+	delay = 0
+	if res, err = client.CallTimeout(time.Second, "get"); err != nil || res.ResultInt() != 42 {
+		t.Fatal("Call within timeout failed:", err)
+	}
+}
+
+func Test_NewClient_unixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xmlrpc")
+	if err != nil {
+		t.Fatal("Unable to finish test", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "one.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal("Unable to finish test", err)
+	}
+	defer listener.Close()
+
+	go http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.WriteString(w, responseInt); err != nil {
+			t.Error(err)
+		}
+	}))
+
+	client := NewClient("unix://"+socket, nil)
+	res, err := client.Call(context.TODO(), "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultInt() != 42 {
+		t.Fatal("Method Call returns wrong result.")
+	}
+}
+
+func Test_Call_preparePayload_funcArg(t *testing.T) {
+	// test expects fail before connection to the server, no record needed
+	res, err := MakeCallAndCreateRecord(t, "", endpointCorrect, "pow", 2, func() {})
+	if err == nil {
+		t.Fatal("No error when args contains function.")
+	}
+	if !strings.Contains(err.Error(), "argument 2: invalid type func") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when args contains function.")
+	}
+}
+
+func Test_Call_errorContainsMethodName(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, wrongEndpoint, endpointWrong, "pow", 2, 9)
+	if err == nil {
+		t.Fatal("No error when endpoint is wrong.")
+	}
+	if !strings.Contains(err.Error(), "call 'pow' with 2 arguments failed") {
+		t.Fatal("Error doesn't contain method name:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when endpoint is wrong.")
+	}
+}
+
+func Test_Call_errorHidesArgs(t *testing.T) {
+	client, stop := CreateClientWithRecorder(t, faultRecord, endpointCorrect)
+	defer stop()
+
+	_, err := client.Call(context.TODO(), "one.vm.action", "terminate", 42)
+	if err == nil {
+		t.Fatal("No error when server returns fault.")
+	}
+	if !strings.Contains(err.Error(), "call 'one.vm.action' with 2 arguments failed") {
+		t.Fatal("Error doesn't contain method name:", err)
+	}
+	if strings.Contains(err.Error(), "terminate") {
+		t.Fatal("Error contains argument value:", err)
+	}
+}
+
+func Test_CallNamed(t *testing.T) {
+	var body []byte
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		return newResponse(http.StatusOK, responseInt), nil
+	})
+
+	client := NewClient(endpointCorrect, nil, WithTransport(transport))
+	res, err := client.CallNamed(context.TODO(), "get", map[string]interface{}{"fruit": "apple", "count": 3})
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultInt() != 42 {
+		t.Fatal("Unexpected result:", res.ResultInt())
+	}
+
+	payload := string(body)
+	if strings.Count(payload, "<param>") != 1 || !strings.Contains(payload, "<params><param><value><struct><member>") {
+		t.Fatal("Payload doesn't contain single struct param:", payload)
+	}
+	if !strings.Contains(payload, "<member><name>fruit</name><value><string>apple</string></value></member>") ||
+		!strings.Contains(payload, "<member><name>count</name><value><int>3</int></value></member>") {
+		t.Fatal("Payload doesn't contain named arguments:", payload)
+	}
+}
+
+func Test_CallMulti(t *testing.T) {
+	response := `<?xml version="1.0"?><methodResponse><params>` +
+		`<param><value><boolean>1</boolean></value></param>` +
+		`<param><value><string>pancake</string></value></param>` +
+		`</params></methodResponse>`
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, response), nil
+	})
+	client := NewClient(endpointCorrect, nil, WithTransport(transport))
+
+	results, err := client.CallMulti(context.TODO(), "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(results) != 2 || !results[0].ResultBoolean() || results[1].ResultString() != "pancake" {
+		t.Fatal("Method CallMulti returns wrong results:", results)
+	}
+
+	res, err := client.Call(context.TODO(), "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Kind() != KindBool {
+		t.Fatal("Method Call doesn't return first param:", res.Kind())
+	}
+}
+
+func Test_CallMulti_singleParam(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, responseInt), nil
+	})
+	client := NewClient(endpointCorrect, nil, WithTransport(transport))
+
+	results, err := client.CallMulti(context.TODO(), "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(results) != 1 || results[0].ResultInt() != 42 {
+		t.Fatal("Method CallMulti returns wrong results:", results)
+	}
+}
+
+type memoryTransport struct {
+	requests [][]byte
+	response []byte
+}
+
+func (m *memoryTransport) RoundTrip(ctx context.Context, request []byte) ([]byte, error) {
+	m.requests = append(m.requests, request)
+	return m.response, nil
+}
+
+func Test_WithCustomTransport(t *testing.T) {
+	transport := &memoryTransport{response: []byte(responseInt)}
+	client := NewClient("", nil, WithCustomTransport(transport))
+
+	res, err := client.Call(context.TODO(), "one.vm.info", 42)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultInt() != 42 {
+		t.Fatal("Unexpected result:", res.ResultInt())
+	}
+	if len(transport.requests) != 1 ||
+		!strings.Contains(string(transport.requests[0]), "<methodName>one.vm.info</methodName>") {
+		t.Fatal("Request isn't sent by custom transport.")
+	}
+}
+
+func Test_WithDryRun(t *testing.T) {
+	type call struct {
+		method string
+		args   []interface{}
+	}
+	var calls []call
+	hook := func(methodName string, args []interface{}) (*Result, error) {
+		calls = append(calls, call{methodName, args})
+		if methodName == "one.vm.action" {
+			return nil, &Fault{Code: 1024, Message: "[one.vm.action] Error getting virtual machine [42]."}
+		}
+		return &Result{kind: KindInt, resInt: 7}, nil
+	}
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Fatal("Request is sent in dry run mode.")
+		return nil, nil
+	})
+	client := NewClient(endpointCorrect, nil, WithTransport(transport), WithDryRun(hook))
+
+	res, err := client.Call(context.TODO(), "one.vm.info", 42)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultInt() != 7 {
+		t.Fatal("Canned result isn't returned:", res.ResultInt())
+	}
+
+	err = client.CallVoid(context.TODO(), "one.vm.action", "terminate", 42)
+	if fault, ok := errors.Cause(err).(*Fault); !ok || fault.Code != 1024 {
+		t.Fatal("Canned fault isn't returned:", err)
+	}
+
+	if _, err = client.Call(context.TODO(), "one.vm.info", func() {}); err == nil {
+		t.Fatal("No error when argument has invalid type.")
+	}
+
+	if len(calls) != 2 || calls[0].method != "one.vm.info" || calls[0].args[0] != 42 ||
+		calls[1].method != "one.vm.action" || len(calls[1].args) != 2 {
+		t.Fatal("Unexpected captured calls:", calls)
+	}
+}
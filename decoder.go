@@ -0,0 +1,26 @@
+package xmlrpc
+
+import "net/http"
+
+// Decoder parses XML-RPC method responses received by any means, e.g. over transports other than HTTP.
+// It must be created by NewDecoder and it's safe for concurrent use.
+type Decoder struct {
+	parser parser
+}
+
+func newParser() parser {
+	return parser{maxDepth: defaultMaxDepth, faultErrors: DefaultFaultErrors}
+}
+
+// NewDecoder is a Decoder constructor, its behaviour can be adjusted by the same options as the behaviour
+// of Client, options unrelated to parsing of responses are ignored
+func NewDecoder(opts ...Option) *Decoder {
+	s := newSettings(http.DefaultClient, opts)
+	return &Decoder{parser: s.parser}
+}
+
+// DecodeResponse parses the XML document of a method response, a fault is returned as an error caused by *Fault
+func (d *Decoder) DecodeResponse(data []byte) (*Result, error) {
+	parser := d.parser
+	return parser.parseResult(data)
+}
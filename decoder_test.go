@@ -0,0 +1,140 @@
+package xmlrpc
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/go-vcr/cassette"
+	"github.com/pkg/errors"
+)
+
+const vmPoolGzip = "records/vm_pool.xml.gz"
+
+func recordedBody(t *testing.T, recorderName string) []byte {
+	c, err := cassette.Load(recorderName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Interactions) == 0 {
+		t.Fatal("No interaction recorded in", recorderName)
+	}
+
+	return []byte(c.Interactions[0].Response.Body)
+}
+
+func Test_Decoder_DecodeResponse(t *testing.T) {
+	res, err := NewDecoder().DecodeResponse(recordedBody(t, parseWhitespace))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Kind() != KindStruct || res.ResultStruct()["int"].ResultInt() != 42 {
+		t.Fatal("Decoder returns wrong result.")
+	}
+	if res.ResultStruct()["string"].ResultString() != "  pancake  " {
+		t.Fatal("Decoder trims string result.")
+	}
+
+	res, err = NewDecoder(WithTrimStrings()).DecodeResponse(recordedBody(t, parseWhitespace))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultStruct()["string"].ResultString() != "pancake" {
+		t.Fatal("Decoder doesn't trim string result.")
+	}
+}
+
+func Test_Decoder_DecodeResponse_fault(t *testing.T) {
+	res, err := NewDecoder().DecodeResponse(recordedBody(t, faultRecord))
+	if err == nil {
+		t.Fatal("No error when response is fault.")
+	}
+	if res != nil {
+		t.Fatal("Decoder returns result when response is fault.")
+	}
+	if fault, ok := errors.Cause(err).(*Fault); !ok || fault.Code != 1024 {
+		t.Fatal("Error isn't caused by fault:", err)
+	}
+}
+
+func Test_Decoder_DecodeResponse_malformed(t *testing.T) {
+	if _, err := NewDecoder().DecodeResponse(recordedBody(t, wrongRootTag)); err == nil {
+		t.Fatal("No error when response has wrong root tag.")
+	}
+}
+
+// gzipFixture returns the decompressed content of a gzip-compressed fixture
+func gzipFixture(t *testing.T, name string) []byte {
+	file, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func Test_Decoder_DecodeResponse_gzipFixture(t *testing.T) {
+	res, err := NewDecoder().DecodeResponse(gzipFixture(t, vmPoolGzip))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	vms, err := res.StructSlice()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(vms) != 100 || vms[99]["NAME"].ResultString() != "vm-99" {
+		t.Fatal("Decoder returns wrong result.")
+	}
+}
+
+func Test_WithGzipResponses(t *testing.T) {
+	data, err := ioutil.ReadFile(vmPoolGzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, string(data)), nil
+	})}
+
+	if _, err = NewClient(endpointCorrect, httpClient).Call(context.TODO(), "one.vmpool.info"); err == nil {
+		t.Fatal("Compressed response is decompressed without the option.")
+	}
+
+	size := len(gzipFixture(t, vmPoolGzip))
+	client := NewClient(endpointCorrect, httpClient, WithGzipResponses(size))
+	res, err := client.Call(context.TODO(), "one.vmpool.info")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Len() != 100 {
+		t.Fatal("Method Call returns wrong result.")
+	}
+
+	client = NewClient(endpointCorrect, httpClient, WithGzipResponses(size-1))
+	_, err = client.Call(context.TODO(), "one.vmpool.info")
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("decompressed size exceeds maximum size %d", size-1)) {
+		t.Fatal("Unexpected error when decompressed response is too large:", err)
+	}
+}
+
+func Test_WithGzipResponses_corrupted(t *testing.T) {
+	_, err := NewDecoder(WithGzipResponses(1024)).DecodeResponse(append([]byte{}, gzipMagic...))
+	if err == nil || !strings.Contains(err.Error(), "cannot decompress XML RPC response") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
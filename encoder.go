@@ -0,0 +1,157 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/pkg/errors"
+)
+
+// Encoder builds XML-RPC method call documents without sending them, e.g. for transports other than HTTP.
+// It's safe for concurrent use, the zero value encodes with the defaults except the XML declaration, which is
+// omitted, and large base64 values, which aren't streamed.
+type Encoder struct {
+	indentPrefix     string
+	indent           string
+	byteSliceAsArray bool
+	bigIntAsI8       bool
+	booleanText      bool
+	omitEmptyParams  bool
+	base64StreamSize int
+	durationUnit     time.Duration
+	declaration      string
+	validateMethod   bool
+	stringers        bool
+
+	blobs [][]byte
+}
+
+func newEncoder() Encoder {
+	return Encoder{
+		base64StreamSize: defaultBase64StreamSize,
+		durationUnit:     defaultDurationUnit,
+		declaration:      xmlInstruction,
+	}
+}
+
+// NewEncoder is an Encoder constructor, its behaviour can be adjusted by the same options as the behaviour
+// of Client, options unrelated to encoding of requests are ignored
+func NewEncoder(opts ...Option) *Encoder {
+	s := newSettings(http.DefaultClient, opts)
+	return &s.encoder
+}
+
+// EncodeCall returns the XML document of the method call with the given arguments
+func (e *Encoder) EncodeCall(methodName string, args ...interface{}) ([]byte, error) {
+	encoder := *e
+	buffer, err := encoder.encodeCall(methodName, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "payload preparation failed")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// EncodeValue returns the XML 'value' element of the argument, which can be also a *Result, e.g. to build responses
+func (e *Encoder) EncodeValue(arg interface{}) ([]byte, error) {
+	encoder := *e
+	v, err := encoder.toValue(arg)
+	if err != nil {
+		return nil, errors.Wrap(err, "value encoding failed")
+	}
+
+	doc := etree.NewDocument()
+	doc.SetRoot(v.toValue().Element)
+	buffer := new(bytes.Buffer)
+	if _, err = doc.WriteTo(buffer); err != nil {
+		return nil, errors.Wrap(err, "write to buffer failed")
+	}
+
+	if len(encoder.blobs) > 0 {
+		if buffer, err = encoder.writeBlobs(buffer.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// encodeCall builds the method call document, it's called on a copy of the encoder which holds per-call state
+func (e *Encoder) encodeCall(methodName string, args ...interface{}) (*bytes.Buffer, error) {
+	payload, err := e.buildPayload(methodName, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := new(bytes.Buffer)
+	if _, err := payload.WriteTo(buffer); err != nil {
+		return nil, errors.Wrap(err, "write to buffer failed")
+	}
+
+	if len(e.blobs) > 0 {
+		return e.writeBlobs(buffer.Bytes())
+	}
+
+	return buffer, nil
+}
+
+// writePayload writes the built payload to w, blobs are encoded directly into w
+func (e *Encoder) writePayload(w io.Writer, payload *payload) error {
+	if len(e.blobs) == 0 {
+		_, err := payload.WriteTo(w)
+		return errors.Wrap(err, "write failed")
+	}
+
+	buffer := new(bytes.Buffer)
+	if _, err := payload.WriteTo(buffer); err != nil {
+		return errors.Wrap(err, "write to buffer failed")
+	}
+
+	return e.writeBlobsTo(w, buffer.Bytes())
+}
+
+// buildPayload builds the method call document with placeholders of streamed blobs
+func (e *Encoder) buildPayload(methodName string, args ...interface{}) (*payload, error) {
+	if methodName == "" {
+		return nil, ErrEmptyMethod
+	}
+	if e.validateMethod && !validMethodName(methodName) {
+		return nil, errors.Errorf("invalid method name '%s': only letters, digits, '_', '.', ':' and '/' are allowed",
+			methodName)
+	}
+
+	payload := newPayload(methodName, e.declaration)
+	for i, arg := range args {
+		value, err := e.toValue(arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "method arguments parsing failed: argument %d", i+1)
+		}
+		payload.addParam(value)
+	}
+	if len(args) == 0 && e.omitEmptyParams {
+		payload.removeParams()
+	}
+
+	if e.indentPrefix != "" || e.indent != "" {
+		payload.indent(e.indentPrefix, e.indent)
+	}
+
+	return payload, nil
+}
+
+// validMethodName checks the method name consists only of characters allowed by the XML-RPC specification
+func validMethodName(methodName string) bool {
+	for _, r := range methodName {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '.', r == ':', r == '/':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
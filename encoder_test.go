@@ -0,0 +1,226 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Encoder_EncodeCall(t *testing.T) {
+	payload, err := NewEncoder().EncodeCall("one.vm.action", "terminate", 42)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?><methodCall><methodName>one.vm.action</methodName><params>` +
+		`<param><value><string>terminate</string></value></param><param><value><int>42</int></value></param>` +
+		`</params></methodCall>`
+	if string(payload) != expected {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+
+	built, err := NewClient(endpointCorrect, nil).BuildPayload("one.vm.action", "terminate", 42)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !bytes.Equal(payload, built) {
+		t.Fatal("Encoder and client build different payloads.")
+	}
+}
+
+func Test_Encoder_options(t *testing.T) {
+	encoder := NewEncoder(WithoutXMLDeclaration(), WithBooleanText(), WithContentType("application/xml"))
+	payload, err := encoder.EncodeCall("get", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if string(payload) != "<methodCall><methodName>get</methodName><params>"+
+		"<param><value><boolean>true</boolean></value></param></params></methodCall>" {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+}
+
+func Test_Encoder_zeroValue(t *testing.T) {
+	var encoder Encoder
+	payload, err := encoder.EncodeCall("get", 90*time.Second)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if string(payload) != "<methodCall><methodName>get</methodName><params>"+
+		"<param><value><int>90</int></value></param></params></methodCall>" {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+}
+
+func Test_Encoder_httpOptions(t *testing.T) {
+	encoder := NewEncoder(WithTransport(nil), WithDialTimeout(time.Second), WithDisableRedirects(), WithHTTP2(false))
+	if _, err := encoder.EncodeCall("get", 42); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	if _, err := NewDecoder(WithDialTimeout(time.Second), WithDisableRedirects()).DecodeResponse(nil); err == nil {
+		t.Fatal("No error when response is empty.")
+	}
+}
+
+func Test_Encoder_invalidArgs(t *testing.T) {
+	payload, err := NewEncoder().EncodeCall("get", 1, func() {})
+	if err == nil {
+		t.Fatal("No error when argument has invalid type.")
+	}
+	if !strings.Contains(err.Error(), "argument 2") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if payload != nil {
+		t.Fatal("Payload returned when argument has invalid type.")
+	}
+}
+
+func Test_Encoder_methodNameValidation(t *testing.T) {
+	encoder := NewEncoder(WithMethodNameValidation())
+	for _, name := range []string{"get", "one.vm.info", "system/listMethods", "ns:method_2", "A_B.c"} {
+		if _, err := encoder.EncodeCall(name); err != nil {
+			t.Error("Error for valid method name:", name, err)
+		}
+	}
+
+	for _, name := range []string{"one vm", "get-info", "pančake", "get()", "a<b"} {
+		payload, err := encoder.EncodeCall(name)
+		if err == nil {
+			t.Error("No error for invalid method name:", name)
+			continue
+		}
+		if !strings.Contains(err.Error(), "invalid method name") {
+			t.Error("Unexpected error:", err)
+		}
+		if payload != nil {
+			t.Error("Payload returned for invalid method name:", name)
+		}
+	}
+
+	if _, err := NewEncoder().EncodeCall("get-info"); err != nil {
+		t.Fatal("Method name validated without the option:", err)
+	}
+}
+
+type vmState int
+
+const (
+	vmStateInit vmState = iota
+	vmStatePending
+)
+
+func (s vmState) String() string {
+	return [...]string{"INIT", "PENDING"}[s]
+}
+
+type imageName string
+
+func Test_Encoder_namedTypes(t *testing.T) {
+	payload, err := NewEncoder(WithoutXMLDeclaration()).EncodeCall("get", vmStatePending, imageName("ubuntu"))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := "<methodCall><methodName>get</methodName><params><param><value><int>1</int></value></param>" +
+		"<param><value><string>ubuntu</string></value></param></params></methodCall>"
+	if string(payload) != expected {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+}
+
+func Test_Encoder_WithStringers(t *testing.T) {
+	var nilTime *time.Time
+	encoder := NewEncoder(WithoutXMLDeclaration(), WithStringers())
+	payload, err := encoder.EncodeCall("get", vmStatePending, []vmState{vmStateInit}, time.Second,
+		time.Date(2018, 8, 1, 20, 31, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := "<methodCall><methodName>get</methodName><params>" +
+		"<param><value><string>PENDING</string></value></param>" +
+		"<param><value><array><data><value><string>INIT</string></value></data></array></value></param>" +
+		"<param><value><int>1</int></value></param>" +
+		"<param><value><dateTime.iso8601>2018-08-01T20:31:00+0000</dateTime.iso8601></value></param>" +
+		"</params></methodCall>"
+	if string(payload) != expected {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+
+	if _, err = encoder.EncodeCall("get", nilTime); err == nil {
+		t.Fatal("No error when argument is nil pointer.")
+	}
+}
+
+func Test_Encoder_concurrent(t *testing.T) {
+	encoder := NewEncoder()
+	blob := bytes.Repeat([]byte("pancake"), defaultBase64StreamSize)
+	expected, err := encoder.EncodeCall("upload", blob, "done")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			payload, err := encoder.EncodeCall("upload", blob, "done")
+			if err != nil || !bytes.Equal(payload, expected) {
+				t.Error("Concurrent encoding returns different payload:", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func Test_Encoder_EncodeValue_result(t *testing.T) {
+	date := time.Date(1995, 1, 1, 6, 38, 5, 0, time.UTC)
+	res := NewStructResult(map[string]*Result{
+		"name":  NewStringResult("pancake"),
+		"count": NewIntResult(3),
+		"toppings": NewArrayResult(
+			NewBoolResult(true),
+			NewDoubleResult(1.5),
+			NewDateTimeResult(date),
+			NewBase64Result([]byte("1")),
+		),
+	})
+
+	encoded, err := NewEncoder().EncodeValue(res)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := "<value><struct>" +
+		"<member><name>count</name><value><int>3</int></value></member>" +
+		"<member><name>name</name><value><string>pancake</string></value></member>" +
+		"<member><name>toppings</name><value><array><data>" +
+		"<value><boolean>1</boolean></value><value><double>1.5</double></value>" +
+		"<value><dateTime.iso8601>1995-01-01T06:38:05+0000</dateTime.iso8601></value>" +
+		"<value><base64>MQ==</base64></value>" +
+		"</data></array></value></member></struct></value>"
+	if string(encoded) != expected {
+		t.Fatal("Unexpected encoded value:", string(encoded))
+	}
+
+	payload, err := NewEncoder().EncodeCall("set", res.ResultStruct()["toppings"].Index(1))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><double>1.5</double></value></param>") {
+		t.Fatal("Result isn't encoded as argument:", string(payload))
+	}
+}
+
+func Test_Encoder_EncodeValue_invalidResult(t *testing.T) {
+	if _, err := NewEncoder().EncodeValue(NewArrayResult(NewIntResult(1), &Result{})); err == nil {
+		t.Fatal("No error when result is invalid.")
+	}
+	if _, err := NewEncoder().EncodeValue(NewArrayResult(nil)); err == nil {
+		t.Fatal("No error when result is nil.")
+	}
+}
@@ -1,6 +1,10 @@
 package xmlrpc
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
 
 type temporary interface {
 	Temporary() bool // Is the error temporary?
@@ -21,3 +25,103 @@ func IsTimeout(err error) bool {
 	te, ok := errors.Cause(err).(timeout)
 	return ok && te.Timeout()
 }
+
+// ErrEmptyMethod is returned when a call is made with an empty method name, no request is sent then
+var ErrEmptyMethod = errors.New("empty method name")
+
+// ErrNotModified is returned when the server responds with HTTP status 304 to a conditional request,
+// see WithIfNoneMatchKey
+var ErrNotModified = errors.New("not modified")
+
+// Errors which are wrapped by errors of responses with non-2xx HTTP status codes according to the status class,
+// so they can be checked by errors.Is
+var (
+	ErrInformationalStatus = errors.New("unexpected informational response")
+	ErrRedirectStatus      = errors.New("redirect response not followed")
+	ErrClientStatus        = errors.New("client error response")
+	ErrServerStatus        = errors.New("server error response")
+)
+
+// ErrCircuitOpen is returned without sending the request when the circuit breaker is open, see WithCircuitBreaker
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Errors which faults with known OpenNebula fault codes wrap, so they can be checked by errors.Is
+var (
+	ErrAuthentication = errors.New("authentication failed")
+	ErrAuthorization  = errors.New("authorization failed")
+	ErrNoExists       = errors.New("resource doesn't exist")
+	ErrAction         = errors.New("action failed")
+	ErrAPI            = errors.New("wrong method parameters")
+	ErrInternal       = errors.New("internal error")
+	ErrAllocate       = errors.New("allocation failed")
+	ErrLocked         = errors.New("resource is locked")
+)
+
+// DefaultFaultErrors maps OpenNebula fault codes to errors wrapped by faults, it's used by clients
+// created without WithFaultErrors option
+var DefaultFaultErrors = map[int]error{
+	0x0100: ErrAuthentication,
+	0x0200: ErrAuthorization,
+	0x0400: ErrNoExists,
+	0x0800: ErrAction,
+	0x1000: ErrAPI,
+	0x2000: ErrInternal,
+	0x4000: ErrAllocate,
+	0x8000: ErrLocked,
+}
+
+// Fault represents an XML-RPC fault returned by the server
+type Fault struct {
+	Code    int
+	Message string
+	// Details holds values of an optional array member some servers send along with the code and message
+	Details []*Result
+
+	err error
+	raw []byte
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("XML RPC error: %d: %s", f.Code, f.Message)
+}
+
+// Unwrap returns the error which the fault code is mapped to, or nil for unknown codes
+func (f *Fault) Unwrap() error {
+	return f.err
+}
+
+// Raw returns the 'fault' element exactly as received from the server, e.g. for auditing, or nil when the fault
+// wasn't parsed from a 'fault' element, e.g. a fault created from an error struct
+func (f *Fault) Raw() []byte {
+	return f.raw
+}
+
+// callError wraps errors returned from calls, unlike errors of github.com/pkg/errors it supports unwrapping,
+// so the root cause and errors wrapped by it are reachable by errors.Is and errors.As
+type callError struct {
+	err error
+}
+
+func (e *callError) Error() string {
+	return e.err.Error()
+}
+
+// Cause returns the root cause for errors.Cause of github.com/pkg/errors
+func (e *callError) Cause() error {
+	return errors.Cause(e.err)
+}
+
+// Unwrap returns the root cause for errors.Unwrap of the standard library
+func (e *callError) Unwrap() error {
+	return errors.Cause(e.err)
+}
+
+// Format formats the error the same way as the wrapped error, including the stack trace for '%+v'
+func (e *callError) Format(s fmt.State, verb rune) {
+	if formatter, ok := e.err.(fmt.Formatter); ok {
+		formatter.Format(s, verb)
+		return
+	}
+
+	fmt.Fprintf(s, "%s", e.err.Error())
+}
@@ -0,0 +1,214 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/go-vcr/recorder"
+)
+
+const (
+	statusMoved    = "records/status_moved"
+	statusNotFound = "records/status_not_found"
+)
+
+func faultResponse(code int, message string) string {
+	return fmt.Sprintf(`<?xml version="1.0"?><methodResponse><fault><value><struct>`+
+		`<member><name>faultCode</name><value><int>%d</int></value></member>`+
+		`<member><name>faultString</name><value><string>%s</string></value></member>`+
+		`</struct></value></fault></methodResponse>`, code, message)
+}
+
+func faultClient(code int, opts ...Option) *Client {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, faultResponse(code, "[one.vm.info] Request failed.")), nil
+	})
+
+	return NewClient(endpointCorrect, nil, append([]Option{WithTransport(transport)}, opts...)...)
+}
+
+func Test_Fault_noExists(t *testing.T) {
+	client, stop := CreateClientWithRecorder(t, faultRecord, endpointCorrect)
+	defer stop()
+
+	_, err := client.Call(context.TODO(), "one.vm.action", "terminate", 42)
+	if !errors.Is(err, ErrNoExists) {
+		t.Fatal("Error isn't ErrNoExists:", err)
+	}
+	if errors.Is(err, ErrAuthorization) {
+		t.Fatal("Error is ErrAuthorization:", err)
+	}
+
+	var fault *Fault
+	if !errors.As(err, &fault) || fault.Code != 1024 {
+		t.Fatal("Error isn't caused by fault:", err)
+	}
+}
+
+func Test_Fault_Raw(t *testing.T) {
+	client, stop := CreateClientWithRecorder(t, faultRecord, endpointCorrect)
+	defer stop()
+
+	_, err := client.Call(context.TODO(), "one.vm.action", "terminate", 42)
+	var fault *Fault
+	if !errors.As(err, &fault) {
+		t.Fatal("Error isn't caused by fault:", err)
+	}
+
+	expected := `<fault>
+<value><struct>
+<member>
+<name>faultCode</name>
+<value><int>1024</int></value>
+</member>
+<member>
+<name>faultString</name>
+<value><string>[one.vm.action] Error getting virtual machine [42].</string></value>
+</member>
+</struct></value>
+</fault>`
+	if string(fault.Raw()) != expected {
+		t.Fatal("Unexpected raw fault:", string(fault.Raw()))
+	}
+	if !strings.Contains(string(recordedBody(t, faultRecord)), expected) {
+		t.Fatal("Raw fault doesn't match recorded response.")
+	}
+}
+
+func Test_Fault_Raw_errorStruct(t *testing.T) {
+	fault := (&parser{errorCodeKey: "errcode", errorMessageKey: "errmsg"}).errorStructFault(&Result{
+		kind: KindStruct, resStruct: map[string]*Result{
+			"errcode": {kind: KindInt, resInt: 1},
+			"errmsg":  {kind: KindString, resString: "failed"},
+		},
+	})
+	if fault == nil || fault.Raw() != nil {
+		t.Fatal("Fault created from error struct has raw XML.")
+	}
+}
+
+func Test_Fault_authorization(t *testing.T) {
+	_, err := faultClient(512).Call(context.TODO(), "one.vm.info", 42)
+	if !errors.Is(err, ErrAuthorization) {
+		t.Fatal("Error isn't ErrAuthorization:", err)
+	}
+
+	_, err = faultClient(256).Call(context.TODO(), "one.vm.info", 42)
+	if !errors.Is(err, ErrAuthentication) {
+		t.Fatal("Error isn't ErrAuthentication:", err)
+	}
+
+	_, err = faultClient(42).Call(context.TODO(), "one.vm.info", 42)
+	var fault *Fault
+	if !errors.As(err, &fault) || fault.Unwrap() != nil {
+		t.Fatal("Fault with unknown code wraps an error:", err)
+	}
+}
+
+func Test_WithFaultErrors(t *testing.T) {
+	errForbidden := errors.New("forbidden")
+	_, err := faultClient(512, WithFaultErrors(map[int]error{512: errForbidden})).Call(context.TODO(), "one.vm.info", 42)
+	if !errors.Is(err, errForbidden) {
+		t.Fatal("Error isn't mapped by custom mapping:", err)
+	}
+	if errors.Is(err, ErrAuthorization) {
+		t.Fatal("Error is mapped by default mapping:", err)
+	}
+
+	_, err = faultClient(512, WithFaultErrors(nil)).Call(context.TODO(), "one.vm.info", 42)
+	if errors.Is(err, ErrAuthorization) {
+		t.Fatal("Error is mapped when mapping is disabled:", err)
+	}
+}
+
+type vmError struct {
+	code int
+	msg  string
+}
+
+func (e *vmError) Error() string {
+	return fmt.Sprintf("virtual machine error %d: %s", e.code, e.msg)
+}
+
+func Test_WithFaultMapper(t *testing.T) {
+	mapper := func(code int, msg string) error {
+		if code == 1024 {
+			return &vmError{code: code, msg: msg}
+		}
+		return nil
+	}
+
+	_, err := faultClient(1024, WithFaultMapper(mapper)).Call(context.TODO(), "one.vm.info", 42)
+	var vmErr *vmError
+	if !errors.As(err, &vmErr) || vmErr.code != 1024 || vmErr.msg != "[one.vm.info] Request failed." {
+		t.Fatal("Fault isn't mapped to custom error:", err)
+	}
+
+	_, err = faultClient(512, WithFaultMapper(mapper)).Call(context.TODO(), "one.vm.info", 42)
+	var fault *Fault
+	if !errors.As(err, &fault) || fault.Code != 512 {
+		t.Fatal("Error isn't fault when mapper returns nil:", err)
+	}
+}
+
+func Test_statusError_redirect(t *testing.T) {
+	r, err := recorder.New(statusMoved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	httpClient := &http.Client{Transport: r, CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := NewClient(endpointCorrect, httpClient).Call(context.TODO(), "one.vm.info", 42)
+	if !errors.Is(err, ErrRedirectStatus) {
+		t.Fatal("Error isn't ErrRedirectStatus:", err)
+	}
+	if !strings.Contains(err.Error(), "redirect response not followed: code 301 to 'http://127.0.0.1:8000/RPC3'") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when server redirects.")
+	}
+}
+
+func Test_statusError_notFound(t *testing.T) {
+	client, stop := CreateClientWithRecorder(t, statusNotFound, endpointCorrect)
+	defer stop()
+
+	res, err := client.Call(context.TODO(), "one.vm.info", 42)
+	if !errors.Is(err, ErrClientStatus) || errors.Is(err, ErrServerStatus) {
+		t.Fatal("Error isn't ErrClientStatus:", err)
+	}
+	if !strings.Contains(err.Error(), "client error response: code 404") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when endpoint isn't found.")
+	}
+}
+
+func Test_statusError_classes(t *testing.T) {
+	tests := map[int]error{
+		http.StatusProcessing:          ErrInformationalStatus,
+		http.StatusMultipleChoices:     ErrRedirectStatus,
+		http.StatusUnauthorized:        ErrClientStatus,
+		http.StatusInternalServerError: ErrServerStatus,
+		600:                            nil,
+	}
+
+	for code, class := range tests {
+		err := error(&statusError{code: code})
+		if errors.Unwrap(err) != class {
+			t.Fatal("Unexpected class of code", code, ":", errors.Unwrap(err))
+		}
+		if class == nil && err.Error() != "response error: code 600" {
+			t.Fatal("Unexpected error:", err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package xmlrpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// flightGroup coalesces concurrent requests with the same key into a single request whose outcome is shared
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a request in progress, callers joining it wait until done is closed
+type flight struct {
+	done chan struct{}
+	res  []byte
+	err  error
+}
+
+// do runs the request unless a request with the same key is in progress, then its outcome is returned instead.
+// Callers which joined a flight stop waiting for it when their context is done.
+func (g *flightGroup) do(ctx context.Context, key string, request func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.res, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+
+	// the error is left for the callers which joined the flight if the request panics
+	f.err = errors.Errorf("coalesced request panicked")
+	f.res, f.err = request()
+
+	return f.res, f.err
+}
+
+// flightKey identifies requests which can be coalesced, besides the payload it includes the request state
+// taken from the context, i.e. the ETag sent as 'If-None-Match' and the request ID
+func (c *Client) flightKey(ctx context.Context, payload []byte) string {
+	var etag, requestID interface{}
+	if c.etagKey != nil {
+		etag = ctx.Value(c.etagKey)
+	}
+	if c.requestIDKey != nil {
+		requestID = ctx.Value(c.requestIDKey)
+	}
+
+	return cacheKey(fmt.Sprintf("%v\x00%v", etag, requestID), payload)
+}
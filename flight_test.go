@@ -0,0 +1,204 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// joinDelay is how long tests wait for a concurrent call to join the flight in progress
+const joinDelay = 50 * time.Millisecond
+
+func Test_WithSingleFlight(t *testing.T) {
+	var calls int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+	client := NewClient(endpointCorrect, httpClient, WithSingleFlight())
+
+	results := make([]*Result, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	call := func(i int) {
+		defer wg.Done()
+		results[i], errs[i] = client.Call(context.TODO(), "one.vm.info", 42)
+	}
+
+	wg.Add(2)
+	go call(0)
+	<-started
+	go call(1)
+	time.Sleep(joinDelay)
+	close(release)
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatal("Error:", errs[i])
+		}
+		if results[i].ResultInt() != 42 {
+			t.Fatal("Method Call returns wrong result.")
+		}
+	}
+	if results[0] == results[1] {
+		t.Fatal("Coalesced calls share the result.")
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatal("Identical concurrent calls aren't coalesced, requests:", calls)
+	}
+
+	if _, err := client.Call(context.TODO(), "one.vm.info", 42); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Fatal("Finished request is reused, requests:", calls)
+	}
+}
+
+func Test_WithSingleFlight_differentArgs(t *testing.T) {
+	var calls int32
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+	client := NewClient(endpointCorrect, httpClient, WithSingleFlight())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if _, err := client.Call(context.TODO(), "one.vm.info", id); err != nil {
+				t.Error("Error:", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Fatal("Calls with different arguments are coalesced, requests:", calls)
+	}
+}
+
+func Test_flightGroup_panic(t *testing.T) {
+	var g flightGroup
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Panic of the request isn't propagated to its caller.")
+			}
+		}()
+		_, _ = g.do(context.TODO(), "key", func() ([]byte, error) {
+			close(started)
+			<-release
+			panic("request failed")
+		})
+	}()
+	<-started
+
+	joined := make(chan error, 1)
+	go func() {
+		_, err := g.do(context.TODO(), "key", func() ([]byte, error) {
+			return nil, errors.Errorf("request of joined call is sent")
+		})
+		joined <- err
+	}()
+	time.Sleep(joinDelay)
+	close(release)
+
+	select {
+	case err := <-joined:
+		if err == nil || !strings.Contains(err.Error(), "panicked") {
+			t.Fatal("Unexpected error of joined call:", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Joined call is blocked after the request panicked.")
+	}
+
+	if _, err := g.do(context.TODO(), "key", func() ([]byte, error) { return []byte("ok"), nil }); err != nil {
+		t.Fatal("Flight of panicked request isn't removed:", err)
+	}
+}
+
+func Test_WithSingleFlight_etag(t *testing.T) {
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		started <- struct{}{}
+		<-release
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+	client := NewClient(endpointCorrect, httpClient, WithSingleFlight(), WithIfNoneMatchKey(ContextKey("etag")))
+
+	var wg sync.WaitGroup
+	for _, etag := range []string{`"vm-42-7"`, `"vm-42-8"`} {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			if _, err := client.Call(ctx, "one.vm.info", 42); err != nil {
+				t.Error("Error:", err)
+			}
+		}(context.WithValue(context.Background(), ContextKey("etag"), etag))
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Calls with different ETags are coalesced.")
+		}
+	}
+	close(release)
+	wg.Wait()
+}
+
+func Test_flightGroup_canceledWaiter(t *testing.T) {
+	var g flightGroup
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	go func() {
+		_, _ = g.do(context.TODO(), "key", func() ([]byte, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	joined := make(chan error, 1)
+	go func() {
+		_, err := g.do(ctx, "key", func() ([]byte, error) {
+			return nil, errors.Errorf("request of joined call is sent")
+		})
+		joined <- err
+	}()
+	time.Sleep(joinDelay)
+	cancel()
+
+	select {
+	case err := <-joined:
+		if err != context.Canceled {
+			t.Fatal("Unexpected error of canceled call:", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Canceled call waits for the flight.")
+	}
+}
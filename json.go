@@ -0,0 +1,87 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// FromJSON converts a JSON document to a value which can be passed as an argument of a method call. Objects are
+// converted to structs, arrays to arrays, integral numbers to integers and other numbers to doubles. XML-RPC has
+// no nil value, thus JSON null is refused the same way as nil arguments are.
+func FromJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, errors.Wrap(err, "cannot parse JSON")
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.Errorf("cannot parse JSON: unexpected data after top-level value")
+	}
+
+	return fromJSONValue(value)
+}
+
+func fromJSONValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, errors.Errorf("JSON null cannot be converted")
+	case json.Number:
+		if number, err := v.Int64(); err == nil {
+			return number, nil
+		}
+		number, err := v.Float64()
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot convert '%s' to number", v.String())
+		}
+		return number, nil
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, e := range v {
+			converted, err := fromJSONValue(e)
+			if err != nil {
+				return nil, errors.Wrapf(err, "element %d", i)
+			}
+			values[i] = converted
+		}
+		return values, nil
+	case map[string]interface{}:
+		values := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			converted, err := fromJSONValue(e)
+			if err != nil {
+				return nil, errors.Wrapf(err, "member '%s'", k)
+			}
+			values[k] = converted
+		}
+		return values, nil
+	default:
+		return v, nil
+	}
+}
+
+// MarshalJSON encodes the result as JSON using its Value representation
+func (r *Result) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Value())
+}
+
+// CallJSON represents an XML-RPC method call returning the result encoded as JSON. Faults and other failures
+// are returned as Go errors, not as JSON documents.
+func (c *Client) CallJSON(ctx context.Context, methodName string, args ...interface{}) ([]byte, error) {
+	result, err := c.Call(ctx, methodName, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON encoding failed")
+	}
+
+	return data, nil
+}
@@ -0,0 +1,91 @@
+package xmlrpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const callJSON = "records/call_json"
+
+func Test_FromJSON(t *testing.T) {
+	value, err := FromJSON([]byte(`{"name": "pancake", "count": 3, "weight": 1.5, "fresh": true,
+		"toppings": ["syrup", {"fruit": ["banana", 7]}]}`))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	members, ok := value.(map[string]interface{})
+	if !ok {
+		t.Fatal("JSON object isn't converted to map.")
+	}
+	if members["name"] != "pancake" || members["count"] != int64(3) || members["weight"] != 1.5 ||
+		members["fresh"] != true {
+		t.Fatal("JSON scalars are converted wrongly:", members)
+	}
+
+	toppings, ok := members["toppings"].([]interface{})
+	if !ok || len(toppings) != 2 {
+		t.Fatal("JSON array isn't converted to slice.")
+	}
+	fruit, ok := toppings[1].(map[string]interface{})
+	if !ok {
+		t.Fatal("Nested JSON object isn't converted to map.")
+	}
+	fruitList, ok := fruit["fruit"].([]interface{})
+	if !ok || fruitList[0] != "banana" || fruitList[1] != int64(7) {
+		t.Fatal("Nested JSON array is converted wrongly:", fruit["fruit"])
+	}
+
+	client := NewClient(endpointCorrect, nil)
+	if _, err = client.BuildPayload("get", value); err != nil {
+		t.Fatal("Converted JSON cannot be used as an argument:", err)
+	}
+}
+
+func Test_FromJSON_null(t *testing.T) {
+	_, err := FromJSON([]byte(`{"toppings": [1, null]}`))
+	if err == nil {
+		t.Fatal("No error when JSON contains null.")
+	}
+	if !strings.Contains(err.Error(), "member 'toppings': element 1: JSON null cannot be converted") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func Test_FromJSON_invalid(t *testing.T) {
+	if _, err := FromJSON([]byte(`{"name": `)); err == nil {
+		t.Fatal("No error when JSON is invalid.")
+	}
+	if _, err := FromJSON([]byte(`1 2`)); err == nil {
+		t.Fatal("No error when JSON contains multiple values.")
+	}
+}
+
+func Test_CallJSON(t *testing.T) {
+	client, stop := CreateClientWithRecorder(t, callJSON, endpointCorrect)
+	defer stop()
+
+	data, err := client.CallJSON(context.TODO(), "one.vm.info", 42)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := `{"ID":42,"NAME":"pancake","STATE":true,"TEMPLATE":{"CPU":1.5,"DISKS":["root","swap"]}}`
+	if string(data) != expected {
+		t.Fatal("Unexpected JSON:", string(data))
+	}
+}
+
+func Test_CallJSON_fault(t *testing.T) {
+	client, stop := CreateClientWithRecorder(t, faultRecord, endpointCorrect)
+	defer stop()
+
+	data, err := client.CallJSON(context.TODO(), "one.vm.action", "terminate", 42)
+	if err == nil {
+		t.Fatal("No error when server returns fault.")
+	}
+	if data != nil {
+		t.Fatal("CallJSON returns JSON when server returns fault.")
+	}
+}
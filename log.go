@@ -1,8 +1,22 @@
 package xmlrpc
 
+import (
+	"context"
+	"fmt"
+)
+
+// ContextKey is a type of context keys defined by this package
+type ContextKey string
+
+// RequestIDKey is the default context key of a request ID which is included in log messages, see WithRequestIDKey
+const RequestIDKey ContextKey = "xmlrpc-request-id"
+
 // LogErrorFunc can be set from outside the library to allow error logging
 var LogErrorFunc func(string, ...interface{})
 
+// LogDebugFunc can be set from outside the library to allow debug logging of sent requests and received responses
+var LogDebugFunc func(string, ...interface{})
+
 func logError(format string, args ...interface{}) {
 	if LogErrorFunc == nil {
 		return
@@ -10,3 +24,25 @@ func logError(format string, args ...interface{}) {
 
 	LogErrorFunc(format, args...)
 }
+
+func logDebug(format string, args ...interface{}) {
+	if LogDebugFunc == nil {
+		return
+	}
+
+	LogDebugFunc(format, args...)
+}
+
+// logPrefix returns a prefix of log messages containing the request ID stored in the context, if any
+func (c *Client) logPrefix(ctx context.Context) string {
+	if c.requestIDKey == nil {
+		return ""
+	}
+
+	id := ctx.Value(c.requestIDKey)
+	if id == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("request '%v': ", id)
+}
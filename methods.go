@@ -0,0 +1,109 @@
+package xmlrpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const listMethodsName = "system.listMethods"
+
+// methodCache holds the names of methods supported by the server, nil methods means the list isn't fetched yet
+type methodCache struct {
+	mu      sync.Mutex
+	methods map[string]bool
+	fetch   *methodFetch
+}
+
+// methodFetch is a fetch of the list of methods in progress, callers waiting for it wait until done is closed
+type methodFetch struct {
+	done chan struct{}
+}
+
+// HasMethod reports whether the server supports the method. The list of supported methods is fetched by
+// 'system.listMethods' call on first use and cached until InvalidateMethods is called, failed fetch isn't cached.
+// Concurrent callers wait for a single fetch, each of them only until its own context is done.
+func (c *Client) HasMethod(ctx context.Context, methodName string) (bool, error) {
+	for {
+		c.methods.mu.Lock()
+		if c.methods.methods != nil {
+			supported := c.methods.methods[methodName]
+			c.methods.mu.Unlock()
+			return supported, nil
+		}
+
+		f := c.methods.fetch
+		if f == nil {
+			f = &methodFetch{done: make(chan struct{})}
+			c.methods.fetch = f
+			c.methods.mu.Unlock()
+
+			methods, err := c.fetchMethods(ctx, f)
+			if err != nil {
+				return false, errors.Wrap(err, "cannot fetch supported methods")
+			}
+			return methods[methodName], nil
+		}
+		c.methods.mu.Unlock()
+
+		// the list is looked up again when the fetch finishes, a failed fetch is then repeated by this caller
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return false, errors.Wrap(ctx.Err(), "cannot fetch supported methods")
+		}
+	}
+}
+
+// fetchMethods fetches the list of methods without holding the lock, it's cached unless the fetch failed
+// or the methods were invalidated meanwhile
+func (c *Client) fetchMethods(ctx context.Context, f *methodFetch) (map[string]bool, error) {
+	var methods map[string]bool
+	var err error
+	defer func() {
+		c.methods.mu.Lock()
+		if c.methods.fetch == f {
+			c.methods.fetch = nil
+			if err == nil {
+				c.methods.methods = methods
+			}
+		}
+		c.methods.mu.Unlock()
+		close(f.done)
+	}()
+
+	methods, err = c.listMethods(ctx)
+	return methods, err
+}
+
+// InvalidateMethods discards the cached list of supported methods, so it's fetched again by next HasMethod call,
+// the outcome of a fetch in progress isn't cached
+func (c *Client) InvalidateMethods() {
+	c.methods.mu.Lock()
+	defer c.methods.mu.Unlock()
+
+	c.methods.methods = nil
+	c.methods.fetch = nil
+}
+
+func (c *Client) listMethods(ctx context.Context) (map[string]bool, error) {
+	result, err := c.Call(ctx, listMethodsName)
+	if err != nil {
+		return nil, err
+	}
+	if result.Kind() != KindArray {
+		return nil, errors.Errorf("'%s' returned result of kind %s instead of array", listMethodsName, result.Kind())
+	}
+
+	methods := make(map[string]bool, len(result.ResultArray()))
+	for i, method := range result.ResultArray() {
+		if method.Kind() != KindString {
+			return nil, errors.Errorf("'%s' returned element %d of kind %s instead of string", listMethodsName, i,
+				method.Kind())
+		}
+		methods[method.ResultString()] = true
+	}
+
+	return methods, nil
+}
@@ -0,0 +1,119 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const responseMethods = `<?xml version="1.0"?><methodResponse><params><param><value><array><data>` +
+	`<value><string>system.listMethods</string></value><value><string>one.vm.info</string></value>` +
+	`</data></array></value></param></params></methodResponse>`
+
+func Test_HasMethod(t *testing.T) {
+	var calls int32
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return newResponse(http.StatusOK, responseMethods), nil
+	})
+	client := NewClient(endpointCorrect, nil, WithTransport(transport))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, err := client.HasMethod(context.TODO(), "one.vm.info"); err != nil || !ok {
+				t.Error("Supported method isn't reported:", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ok, err := client.HasMethod(context.TODO(), "one.vm.terminate")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if ok {
+		t.Fatal("Unsupported method is reported.")
+	}
+	if calls != 1 {
+		t.Fatal("Methods are fetched more than once:", calls)
+	}
+
+	client.InvalidateMethods()
+	if _, err = client.HasMethod(context.TODO(), "one.vm.info"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if calls != 2 {
+		t.Fatal("Methods aren't fetched again after invalidation:", calls)
+	}
+}
+
+func Test_HasMethod_slowFetch(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		close(started)
+		<-release
+		return newResponse(http.StatusOK, responseMethods), nil
+	})
+	client := NewClient(endpointCorrect, nil, WithTransport(transport))
+
+	fetched := make(chan error, 1)
+	go func() {
+		_, err := client.HasMethod(context.TODO(), "one.vm.info")
+		fetched <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.HasMethod(ctx, "one.vm.info"); errors.Cause(err) != context.DeadlineExceeded {
+		t.Fatal("Waiting caller doesn't respect its context:", err)
+	}
+
+	invalidated := make(chan struct{})
+	go func() {
+		client.InvalidateMethods()
+		close(invalidated)
+	}()
+	select {
+	case <-invalidated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("InvalidateMethods is blocked by fetch in progress.")
+	}
+
+	close(release)
+	if err := <-fetched; err != nil {
+		t.Fatal("Error:", err)
+	}
+	client.methods.mu.Lock()
+	defer client.methods.mu.Unlock()
+	if client.methods.methods != nil {
+		t.Fatal("Methods fetched before invalidation are cached.")
+	}
+}
+
+func Test_HasMethod_wrongResult(t *testing.T) {
+	var calls int
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return newResponse(http.StatusOK, responseInt), nil
+	})
+	client := NewClient(endpointCorrect, nil, WithTransport(transport))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.HasMethod(context.TODO(), "one.vm.info"); err == nil {
+			t.Fatal("No error when methods list isn't an array.")
+		}
+	}
+	if calls != 2 {
+		t.Fatal("Failed fetch of methods is cached.")
+	}
+}
@@ -0,0 +1,451 @@
+package xmlrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Option represents an optional setting of Client, Encoder or Decoder, options unrelated to the one being
+// created are ignored
+type Option func(*settings)
+
+// WithContentType sets the Content-Type header sent with every request, default is 'text/xml'
+func WithContentType(contentType string) Option {
+	return func(s *settings) {
+		s.contentType = contentType
+	}
+}
+
+// WithAccept sets the Accept header sent with every request, e.g. for endpoints serving also other protocols
+// depending on it, default is 'text/xml', empty value omits the header
+func WithAccept(accept string) Option {
+	return func(s *settings) {
+		s.accept = accept
+	}
+}
+
+// transportOption configures the HTTP transport of the client, the default transport is used when the HTTP client
+// has none. Custom RoundTrippers other than *http.Transport cannot be configured and are kept intact.
+func transportOption(configure func(*http.Transport)) Option {
+	return func(s *settings) {
+		var transport *http.Transport
+		switch t := s.client.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = t.Clone()
+		default:
+			return
+		}
+
+		configure(transport)
+		WithTransport(transport)(s)
+	}
+}
+
+// WithTrimStrings enables trimming of surrounding whitespace in string values of responses,
+// other scalar values are always trimmed
+func WithTrimStrings() Option {
+	return func(s *settings) {
+		s.parser.trimStrings = true
+	}
+}
+
+// WithIndent enables indentation of request payloads, each element begins on a new line starting with prefix
+// followed by copies of indent according to the nesting, default is compact payload
+func WithIndent(prefix, indent string) Option {
+	return func(s *settings) {
+		s.encoder.indentPrefix = prefix
+		s.encoder.indent = indent
+	}
+}
+
+// WithXMLDeclaration sets attributes of the XML declaration of requests, default is version '1.0' and encoding
+// 'UTF-8', empty version defaults to '1.0' and empty encoding or standalone attribute is omitted. The payload itself
+// is always encoded in UTF-8.
+func WithXMLDeclaration(version, encoding, standalone string) Option {
+	return func(s *settings) {
+		s.encoder.declaration = xmlDeclaration(version, encoding, standalone)
+	}
+}
+
+// WithoutXMLDeclaration omits the XML declaration of requests
+func WithoutXMLDeclaration() Option {
+	return func(s *settings) {
+		s.encoder.declaration = ""
+	}
+}
+
+// WithoutEmptyParams omits the 'params' element of calls without arguments instead of sending it empty
+func WithoutEmptyParams() Option {
+	return func(s *settings) {
+		s.encoder.omitEmptyParams = true
+	}
+}
+
+// WithMethodNameValidation enables validation of method names against the XML-RPC specification which allows only
+// letters, digits, '_', '.', ':' and '/', calls with other names fail before sending. It's disabled by default because
+// some servers use names out of the specification.
+func WithMethodNameValidation() Option {
+	return func(s *settings) {
+		s.encoder.validateMethod = true
+	}
+}
+
+// WithStringers enables encoding of arguments implementing fmt.Stringer as strings returned by their String method,
+// e.g. of enum types, instead of encoding by their underlying type. Arguments of types with special encoding
+// like time.Time, time.Duration or *big.Int are not affected.
+func WithStringers() Option {
+	return func(s *settings) {
+		s.encoder.stringers = true
+	}
+}
+
+// WithByteSliceAsArray enables encoding of byte slices as arrays of integers instead of base64
+func WithByteSliceAsArray() Option {
+	return func(s *settings) {
+		s.encoder.byteSliceAsArray = true
+	}
+}
+
+// WithBigIntAsI8 enables encoding of *big.Int arguments which fit into 64 bits as 'i8' instead of 'int',
+// values out of this range are always encoded as strings. Without the option, *big.Int values out of the 32-bit
+// range of 'int' are encoded as strings too. Unsigned integers above math.MaxInt32 are encoded
+// as 'i8' too, without the option they are refused as they overflow 'int'.
+func WithBigIntAsI8() Option {
+	return func(s *settings) {
+		s.encoder.bigIntAsI8 = true
+	}
+}
+
+// WithDurationUnit sets the unit in which time.Duration arguments are encoded as 'int', default is time.Second,
+// durations are truncated to whole units
+func WithDurationUnit(unit time.Duration) Option {
+	return func(s *settings) {
+		if unit > 0 {
+			s.encoder.durationUnit = unit
+		}
+	}
+}
+
+// WithEndpointResolver sets a function which returns the endpoint of each request instead of the endpoint passed
+// to NewClient, e.g. to choose one of multiple frontends, a resolution error fails the request. Retries of a call
+// resolve the endpoint again. Unix domain socket endpoints cannot be resolved.
+func WithEndpointResolver(resolver func(ctx context.Context) (string, error)) Option {
+	return func(s *settings) {
+		s.resolver = resolver
+	}
+}
+
+// WithRequestIDKey sets the context key of a request ID which is included in log messages, default is RequestIDKey,
+// nil disables the lookup
+func WithRequestIDKey(key interface{}) Option {
+	return func(s *settings) {
+		s.requestIDKey = key
+	}
+}
+
+// WithIfNoneMatchKey sets the context key of an entity tag which is sent in If-None-Match header, so the server
+// can respond with HTTP status 304 when the response would be the same, such calls fail with ErrNotModified.
+// The value stored in the call context must be a string, calls without it are sent unconditionally.
+func WithIfNoneMatchKey(key interface{}) Option {
+	return func(s *settings) {
+		s.etagKey = key
+	}
+}
+
+// WithResponseValidator sets a function which validates the raw response body before it's parsed,
+// a validation error is returned from the call and the response isn't parsed
+func WithResponseValidator(validator func([]byte) error) Option {
+	return func(s *settings) {
+		s.validator = validator
+	}
+}
+
+// WithRetry enables retries of requests failed with a temporary network error or with HTTP status 429 or 503,
+// attempts is the maximum number of attempts including the first one, backoff is the base of the exponentially
+// growing wait between attempts which is randomized by jitter, a longer wait requested by the server
+// in Retry-After header is honored and the total wait is capped by the call context deadline.
+// A temporary network error like a timeout can occur after the server received the request, so the retried
+// method may be executed more than once and the caller must make sure the methods called are safe to repeat.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(s *settings) {
+		s.retry = retryPolicy{attempts: attempts, backoff: backoff}
+	}
+}
+
+// WithSingleFlight coalesces concurrent calls with the same method name and arguments into a single request,
+// whose response is shared by all of them, e.g. to deduplicate reads. The request is sent with the context
+// of the first call, so its cancellation fails all the coalesced calls, other calls stop waiting when their own
+// context is done. Only calls with the same ETag and request ID in their contexts are coalesced. Streamed requests
+// and requests to endpoints chosen by WithEndpointResolver are not coalesced.
+func WithSingleFlight() Option {
+	return func(s *settings) {
+		s.flights = &flightGroup{}
+	}
+}
+
+// WithResultCache enables caching of results of calls of the methods, which should be read-only, for the TTL,
+// further calls with the same arguments return copies of the cached results without sending a request.
+// Failed calls are not cached. Calls of these methods are sent buffered even with WithStreamingRequests, because
+// their payload is encoded first to look the results up.
+func WithResultCache(ttl time.Duration, methods []string) Option {
+	return func(s *settings) {
+		s.cache = newResultCache(ttl, methods)
+	}
+}
+
+// WithCircuitBreaker stops sending requests after threshold consecutive failed requests, calls then fail
+// with ErrCircuitOpen for the cooldown, after which a single probe request is sent and its success resumes sending,
+// otherwise the cooldown starts again. Faults don't count as failures. Threshold lower than 1 disables the breaker.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(s *settings) {
+		s.breaker = nil
+		if threshold > 0 {
+			s.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown}
+		}
+	}
+}
+
+// WithBooleanText enables encoding of boolean arguments as 'true' and 'false' instead of '1' and '0'
+func WithBooleanText() Option {
+	return func(s *settings) {
+		s.encoder.booleanText = true
+	}
+}
+
+// WithReauth enables refreshing of credentials by reauth when a call fails with a fault for which shouldReauth
+// returns true, the call is then repeated once with the same arguments, so the refreshed credentials must be picked
+// up by the transport, e.g. as a header or a cookie. Nil shouldReauth matches faults mapped to ErrAuthentication.
+// The call is repeated only after such a fault, never after transport or other errors, but the client cannot know
+// whether the server executed the method before the fault, so the caller must make sure the method is safe to repeat.
+func WithReauth(reauth func(ctx context.Context) error, shouldReauth func(fault *Fault) bool) Option {
+	return func(s *settings) {
+		if shouldReauth == nil {
+			shouldReauth = func(fault *Fault) bool {
+				return fault.err == ErrAuthentication
+			}
+		}
+		s.reauth = reauth
+		s.shouldReauth = shouldReauth
+	}
+}
+
+// WithCustomTransport sends requests by the transport instead of HTTP, HTTP related options have no effect then
+func WithCustomTransport(transport Transport) Option {
+	return func(s *settings) {
+		s.transport = transport
+	}
+}
+
+// WithDryRun replaces sending of calls by the hook which receives the method name and arguments and returns
+// a canned result or error, arguments are still encoded to report invalid ones, useful for testing of code using
+// the client
+func WithDryRun(hook func(methodName string, args []interface{}) (*Result, error)) Option {
+	return func(s *settings) {
+		s.dryRun = hook
+	}
+}
+
+// WithStreamingRequests encodes request bodies directly into the connection instead of buffering them,
+// the body is sent with chunked transfer encoding. Failed requests aren't retried then, because the body
+// cannot be sent again, and the maximum request size is checked while the body is being sent.
+func WithStreamingRequests() Option {
+	return func(s *settings) {
+		s.streaming = true
+	}
+}
+
+// WithBaseContext sets the context used by calls made with context.Background or context.TODO,
+// so cancelling it aborts such calls, e.g. when a daemon shuts down
+func WithBaseContext(ctx context.Context) Option {
+	return func(s *settings) {
+		s.baseCtx = ctx
+	}
+}
+
+// WithTransport sets the transport used to make HTTP requests, the HTTP client passed to NewClient isn't modified
+func WithTransport(transport http.RoundTripper) Option {
+	return func(s *settings) {
+		client := *s.client
+		client.Transport = transport
+		s.client = &client
+	}
+}
+
+// WithLenientIntegers enables parsing of non-standard integers in responses with '0x', '0o' or '0b' prefix
+// as hexadecimal, octal or binary numbers, integers with leading zeros like '007' stay decimal
+func WithLenientIntegers() Option {
+	return func(s *settings) {
+		s.parser.lenientInts = true
+	}
+}
+
+// WithLenientDoubles enables parsing of non-standard doubles in responses with whitespace between the sign
+// and the number, e.g. '+ 1.5', surrounding whitespace and a leading '+' are always accepted
+func WithLenientDoubles() Option {
+	return func(s *settings) {
+		s.parser.lenientDoubles = true
+	}
+}
+
+// WithGzipResponses enables decompression of gzip-compressed response bodies recognized by the magic number,
+// e.g. of servers compressing bodies without Content-Encoding header, responses decompressed to more than maxSize
+// bytes are refused. Decompression is disabled by default.
+func WithGzipResponses(maxSize int) Option {
+	return func(s *settings) {
+		s.parser.maxGzip = maxSize
+	}
+}
+
+// WithDecimalSeparator sets the decimal separator of doubles in responses, e.g. ',' for servers formatting numbers
+// by their locale, the separator is replaced by '.' before parsing, default is '.' only
+func WithDecimalSeparator(separator string) Option {
+	return func(s *settings) {
+		if separator != "." {
+			s.parser.decimalSep = separator
+		}
+	}
+}
+
+// WithAllowEmptyResponse accepts responses with an empty body, e.g. of fire-and-forget methods, such responses
+// return a result of KindInvalid instead of an error
+func WithAllowEmptyResponse() Option {
+	return func(s *settings) {
+		s.parser.allowEmpty = true
+	}
+}
+
+// WithMaxDepth sets the maximum nesting depth of arrays and structs in responses, default is 100,
+// zero means unlimited depth
+func WithMaxDepth(depth int) Option {
+	return func(s *settings) {
+		s.parser.maxDepth = depth
+	}
+}
+
+// WithMaxElements sets the maximum total number of array elements and struct members in a response,
+// default is zero which means unlimited number. The limit is checked while results are built from the parsed
+// XML document, so it bounds only the allocation of results, not the memory used to parse the response.
+func WithMaxElements(elements int) Option {
+	return func(s *settings) {
+		s.parser.maxElements = elements
+	}
+}
+
+// WithFaultErrors sets the mapping of fault codes to errors wrapped by faults, default is DefaultFaultErrors,
+// nil disables the mapping
+func WithFaultErrors(faultErrors map[int]error) Option {
+	return func(s *settings) {
+		s.parser.faultErrors = faultErrors
+	}
+}
+
+// WithFaultMapper sets a function which translates faults to custom errors returned from calls,
+// when it returns nil the fault itself is returned
+func WithFaultMapper(mapper func(code int, message string) error) Option {
+	return func(s *settings) {
+		s.faultMapper = mapper
+	}
+}
+
+// WithParseStats enables collecting of the number of parsed values of each kind, see Result.Stats
+func WithParseStats() Option {
+	return func(s *settings) {
+		s.parser.collectStats = true
+	}
+}
+
+// WithMaxBase64Size sets the maximum size of a decoded base64 value in a response, larger values are rejected
+// before they're decoded, default is zero which means unlimited size
+func WithMaxBase64Size(size int) Option {
+	return func(s *settings) {
+		s.parser.maxBase64 = size
+	}
+}
+
+// WithDocument retains the parsed XML document of the response in the result, see Result.Document,
+// it's disabled by default to avoid keeping the document in memory
+func WithDocument() Option {
+	return func(s *settings) {
+		s.parser.keepDocument = true
+	}
+}
+
+// WithErrorStruct enables conversion of struct responses with a non-zero integer member codeKey and a string
+// member msgKey to a Fault, for servers which don't report errors as proper XML-RPC faults
+func WithErrorStruct(codeKey, msgKey string) Option {
+	return func(s *settings) {
+		s.parser.errorCodeKey = codeKey
+		s.parser.errorMessageKey = msgKey
+	}
+}
+
+// WithHTTP2 enables or disables HTTP/2 for the HTTP transport, when enabled HTTP/2 is attempted even if the transport
+// uses custom dial or TLS settings
+func WithHTTP2(enabled bool) Option {
+	return transportOption(func(transport *http.Transport) {
+		transport.ForceAttemptHTTP2 = enabled
+		if !enabled {
+			// non-nil empty map disables HTTP/2 support
+			transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		}
+	})
+}
+
+// WithTLSConfig sets the TLS configuration of the HTTP transport, e.g. a pool of trusted CAs or client certificates
+// for frontends with self-signed certificates. It has no effect when the HTTP client uses a RoundTripper other than
+// *http.Transport, e.g. a recording or instrumenting wrapper, and it's dropped by a later WithTransport option.
+func WithTLSConfig(config *tls.Config) Option {
+	return transportOption(func(transport *http.Transport) {
+		transport.TLSClientConfig = config
+	})
+}
+
+// WithMaxConnsPerHost limits the number of connections to the endpoint including connections in use, further requests
+// wait for a free connection, zero means no limit. Like WithTLSConfig, it has no effect with a RoundTripper other
+// than *http.Transport and it's dropped by a later WithTransport option.
+func WithMaxConnsPerHost(n int) Option {
+	return transportOption(func(transport *http.Transport) {
+		transport.MaxConnsPerHost = n
+	})
+}
+
+// WithDialTimeout sets the maximum amount of time a dial of a new connection waits, it doesn't limit reading
+// of the response which is controlled by the call context
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(s *settings) {
+		s.dialer = &net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}
+		transportOption(func(transport *http.Transport) {
+			transport.DialContext = s.dialer.DialContext
+		})(s)
+	}
+}
+
+// WithDisableRedirects refuses to follow HTTP redirects, so the endpoint cannot be silently changed,
+// a redirect response is returned as an error instead
+func WithDisableRedirects() Option {
+	return func(s *settings) {
+		client := *s.client
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return errors.Errorf("redirect to '%s' refused", req.URL)
+		}
+		s.client = &client
+	}
+}
+
+// WithMaxRequestSize sets the maximum size of request payloads in bytes, larger payloads are refused before
+// sending, default is zero which means unlimited size
+func WithMaxRequestSize(size int64) Option {
+	return func(s *settings) {
+		s.maxRequestSize = size
+	}
+}
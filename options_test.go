@@ -0,0 +1,589 @@
+package xmlrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dnaeon/go-vcr/cassette"
+	"github.com/pkg/errors"
+)
+
+const (
+	redirect    = "records/redirect"
+	reauth      = "records/reauth"
+	notModified = "records/not_modified"
+)
+
+const responseInt = `<?xml version="1.0"?><methodResponse><params><param><value><int>42</int></value></param>` +
+	`</params></methodResponse>`
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newResponse(code int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Header:     http.Header{"Content-Type": []string{"text/xml"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func headerRecordingClient(headers *http.Header) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*headers = req.Header
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+}
+
+func Test_WithContentType_default(t *testing.T) {
+	var headers http.Header
+	client := NewClient(endpointCorrect, headerRecordingClient(&headers))
+	if _, err := client.Call(context.TODO(), "get"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if headers.Get("Content-Type") != "text/xml" {
+		t.Fatal("Unexpected Content-Type header:", headers.Get("Content-Type"))
+	}
+}
+
+func Test_WithContentType(t *testing.T) {
+	var headers http.Header
+	client := NewClient(endpointCorrect, headerRecordingClient(&headers), WithContentType("application/xml"))
+	if _, err := client.Call(context.TODO(), "get"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if headers.Get("Content-Type") != "application/xml" {
+		t.Fatal("Unexpected Content-Type header:", headers.Get("Content-Type"))
+	}
+}
+
+func Test_WithAccept(t *testing.T) {
+	var headers http.Header
+	client := NewClient(endpointCorrect, headerRecordingClient(&headers))
+	if _, err := client.Call(context.TODO(), "get"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if headers.Get("Accept") != "text/xml" {
+		t.Fatal("Unexpected default Accept header:", headers.Get("Accept"))
+	}
+
+	client = NewClient(endpointCorrect, headerRecordingClient(&headers), WithAccept("application/xml, text/xml"))
+	if _, err := client.Call(context.TODO(), "get"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if headers.Get("Accept") != "application/xml, text/xml" {
+		t.Fatal("Unexpected Accept header:", headers.Get("Accept"))
+	}
+
+	client = NewClient(endpointCorrect, headerRecordingClient(&headers), WithAccept(""))
+	if _, err := client.Call(context.TODO(), "get"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if _, ok := headers["Accept"]; ok {
+		t.Fatal("Accept header sent although it's disabled.")
+	}
+}
+
+func Test_WithTransport(t *testing.T) {
+	called := false
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return newResponse(http.StatusOK, responseInt), nil
+	})
+
+	httpClient := &http.Client{}
+	client := NewClient(endpointCorrect, httpClient, WithTransport(transport))
+	res, err := client.Call(context.TODO(), "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !called {
+		t.Fatal("Transport wasn't used.")
+	}
+	if res.ResultInt() != 42 {
+		t.Fatal("Method Call returns wrong result.")
+	}
+	if httpClient.Transport != nil {
+		t.Fatal("HTTP client passed to NewClient was modified.")
+	}
+}
+
+func Test_WithMethodNameValidation(t *testing.T) {
+	called := false
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+
+	client := NewClient(endpointCorrect, httpClient, WithMethodNameValidation())
+	res, err := client.Call(context.TODO(), "one.vm info")
+	if err == nil {
+		t.Fatal("No error when method name is invalid.")
+	}
+	if !strings.Contains(err.Error(), "invalid method name 'one.vm info'") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil || called {
+		t.Fatal("Request with invalid method name was sent.")
+	}
+
+	if _, err = client.Call(context.TODO(), "one.vm.info"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !called {
+		t.Fatal("Request with valid method name wasn't sent.")
+	}
+}
+
+func Test_WithEndpointResolver(t *testing.T) {
+	var urls []string
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		urls = append(urls, req.URL.String())
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+
+	frontends := []string{"http://one-1:2633/RPC2", "http://one-2:2633/RPC2"}
+	var next int
+	client := NewClient(endpointCorrect, httpClient, WithEndpointResolver(func(ctx context.Context) (string, error) {
+		endpoint := frontends[next%len(frontends)]
+		next++
+		return endpoint, nil
+	}))
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Call(context.TODO(), "get"); err != nil {
+			t.Fatal("Error:", err)
+		}
+	}
+	expected := []string{frontends[0], frontends[1], frontends[0]}
+	if strings.Join(urls, " ") != strings.Join(expected, " ") {
+		t.Fatal("Unexpected endpoints:", urls)
+	}
+}
+
+func Test_WithEndpointResolver_error(t *testing.T) {
+	called := false
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+	client := NewClient(endpointCorrect, httpClient, WithEndpointResolver(func(ctx context.Context) (string, error) {
+		return "", errors.New("no frontend available")
+	}))
+
+	res, err := client.Call(context.TODO(), "get")
+	if err == nil || !strings.Contains(err.Error(), "endpoint resolution failed: no frontend available") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil || called {
+		t.Fatal("Request sent although endpoint resolution failed.")
+	}
+}
+
+func Test_WithIfNoneMatchKey(t *testing.T) {
+	type etagKey struct{}
+	var headers http.Header
+	client := NewClient(endpointCorrect, headerRecordingClient(&headers), WithIfNoneMatchKey(etagKey{}))
+
+	ctx := context.WithValue(context.Background(), etagKey{}, `"vm-42-7"`)
+	if _, err := client.Call(ctx, "one.vm.info", 42); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if headers.Get("If-None-Match") != `"vm-42-7"` {
+		t.Fatal("Unexpected If-None-Match header:", headers.Get("If-None-Match"))
+	}
+
+	if _, err := client.Call(context.TODO(), "one.vm.info", 42); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if _, ok := headers["If-None-Match"]; ok {
+		t.Fatal("If-None-Match header sent without entity tag in context.")
+	}
+}
+
+func Test_WithIfNoneMatchKey_notModified(t *testing.T) {
+	client, stop := CreateClientWithRecorder(t, notModified, endpointCorrect, WithIfNoneMatchKey(ContextKey("etag")))
+	defer stop()
+
+	ctx := context.WithValue(context.Background(), ContextKey("etag"), `"vm-42-7"`)
+	res, err := client.Call(ctx, "one.vm.info", 42)
+	if errors.Cause(err) != ErrNotModified {
+		t.Fatal("Error isn't ErrNotModified:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when response isn't modified.")
+	}
+}
+
+// sequenceClient replays responses of all recorded interactions one by one regardless of requests
+func sequenceClient(t *testing.T, recorderName string, calls *int) *http.Client {
+	c, err := cassette.Load(recorderName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if *calls >= len(c.Interactions) {
+			return nil, errors.New("no more recorded interactions")
+		}
+		interaction := c.Interactions[*calls]
+		*calls++
+		return newResponse(interaction.Response.Code, interaction.Response.Body), nil
+	})}
+}
+
+func Test_WithReauth(t *testing.T) {
+	var calls, reauths int
+	client := NewClient(endpointCorrect, sequenceClient(t, reauth, &calls), WithReauth(func(ctx context.Context) error {
+		reauths++
+		return nil
+	}, nil))
+
+	res, err := client.Call(context.TODO(), "one.vm.info", 42)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultInt() != 42 {
+		t.Fatal("Method Call returns wrong result.")
+	}
+	if calls != 2 || reauths != 1 {
+		t.Fatal("Call isn't repeated once after reauthentication, calls:", calls, "reauths:", reauths)
+	}
+}
+
+func Test_WithReauth_failed(t *testing.T) {
+	var calls int
+	client := NewClient(endpointCorrect, sequenceClient(t, reauth, &calls), WithReauth(func(ctx context.Context) error {
+		return errors.New("invalid password")
+	}, nil))
+
+	res, err := client.Call(context.TODO(), "one.vm.info", 42)
+	if err == nil || !strings.Contains(err.Error(), "reauthentication failed: invalid password") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil || calls != 1 {
+		t.Fatal("Call is repeated after failed reauthentication.")
+	}
+}
+
+func Test_WithReauth_otherFault(t *testing.T) {
+	var reauths int
+	client := faultClient(0x400, WithReauth(func(ctx context.Context) error {
+		reauths++
+		return nil
+	}, nil))
+
+	if _, err := client.Call(context.TODO(), "one.vm.info", 42); err == nil {
+		t.Fatal("No error when server returns fault.")
+	}
+	if reauths != 0 {
+		t.Fatal("Reauthentication after fault other than authentication.")
+	}
+
+	client = faultClient(0x400, WithReauth(func(ctx context.Context) error {
+		reauths++
+		return nil
+	}, func(fault *Fault) bool {
+		return fault.Code == 0x400
+	}))
+	if _, err := client.Call(context.TODO(), "one.vm.info", 42); err == nil {
+		t.Fatal("No error when server returns fault.")
+	}
+	if reauths != 1 {
+		t.Fatal("Custom reauthentication condition isn't used, reauths:", reauths)
+	}
+}
+
+func transportOf(t *testing.T, client *Client) *http.Transport {
+	transport, ok := client.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatal("Client doesn't use *http.Transport.")
+	}
+
+	return transport
+}
+
+func Test_WithHTTP2(t *testing.T) {
+	client := NewClient(endpointCorrect, nil, WithHTTP2(true))
+	if !transportOf(t, client).ForceAttemptHTTP2 {
+		t.Fatal("HTTP/2 isn't enabled.")
+	}
+
+	client = NewClient(endpointCorrect, nil, WithHTTP2(false))
+	transport := transportOf(t, client)
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil || len(transport.TLSNextProto) != 0 {
+		t.Fatal("HTTP/2 isn't disabled.")
+	}
+
+	if http.DefaultTransport.(*http.Transport).TLSNextProto != nil {
+		t.Fatal("Default transport was modified.")
+	}
+}
+
+func Test_WithHTTP2_roundTrip(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			w.WriteHeader(http.StatusHTTPVersionNotSupported)
+			return
+		}
+		if _, err := io.WriteString(w, responseInt); err != nil {
+			t.Error(err)
+		}
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := NewClient(server.URL, server.Client(), WithHTTP2(true))
+	res, err := client.Call(context.TODO(), "get")
+	if err != nil {
+		t.Skip("HTTP/2 round trip unavailable:", err)
+	}
+	if res.ResultInt() != 42 {
+		t.Fatal("Method Call returns wrong result.")
+	}
+}
+
+func Test_WithTLSConfig(t *testing.T) {
+	config := &tls.Config{InsecureSkipVerify: true}
+	client := NewClient(endpointCorrect, nil, WithTLSConfig(config))
+	if transportOf(t, client).TLSClientConfig != config {
+		t.Fatal("TLS config isn't applied.")
+	}
+	if http.DefaultTransport.(*http.Transport).TLSClientConfig == config {
+		t.Fatal("Default transport was modified.")
+	}
+}
+
+func Test_WithTLSConfig_roundTrip(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.WriteString(w, responseInt); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(server.URL, nil).Call(context.TODO(), "get"); err == nil {
+		t.Fatal("No error when server certificate is untrusted.")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	res, err := NewClient(server.URL, nil, WithTLSConfig(&tls.Config{RootCAs: pool})).Call(context.TODO(), "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultInt() != 42 {
+		t.Fatal("Method Call returns wrong result.")
+	}
+}
+
+func Test_WithMaxConnsPerHost(t *testing.T) {
+	client := NewClient(endpointCorrect, nil, WithMaxConnsPerHost(4))
+	if transportOf(t, client).MaxConnsPerHost != 4 {
+		t.Fatal("Maximum connections per host aren't set.")
+	}
+	if http.DefaultTransport.(*http.Transport).MaxConnsPerHost != 0 {
+		t.Fatal("Default transport was modified.")
+	}
+}
+
+func Test_transportOptions_customRoundTripper(t *testing.T) {
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, responseInt), nil
+	})}
+	client := NewClient(endpointCorrect, httpClient, WithTLSConfig(&tls.Config{}), WithMaxConnsPerHost(4))
+	if _, ok := client.client.Transport.(roundTripFunc); !ok {
+		t.Fatalf("Custom RoundTripper is replaced: %T", client.client.Transport)
+	}
+
+	client = NewClient(endpointCorrect, nil, WithTLSConfig(&tls.Config{}), WithTransport(httpClient.Transport))
+	if _, ok := client.client.Transport.(roundTripFunc); !ok {
+		t.Fatalf("Transport set by later option is replaced: %T", client.client.Transport)
+	}
+}
+
+func Test_WithDialTimeout(t *testing.T) {
+	client := NewClient(endpointCorrect, nil, WithDialTimeout(3*time.Second))
+	if client.dialer == nil || client.dialer.Timeout != 3*time.Second {
+		t.Fatal("Dial timeout isn't set.")
+	}
+	if transportOf(t, client).DialContext == nil {
+		t.Fatal("Transport doesn't use the dialer.")
+	}
+}
+
+func Test_WithDisableRedirects(t *testing.T) {
+	res, err := MakeCallWithOptionsAndCreateRecord(t, redirect, endpointCorrect, []Option{WithDisableRedirects()},
+		"one.vm.info", 42)
+	if err == nil {
+		t.Fatal("No error when server redirects.")
+	}
+	if !strings.Contains(err.Error(), "redirect to 'http://127.0.0.1:8000/login' refused") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when server redirects.")
+	}
+}
+
+func Test_redirect_followed(t *testing.T) {
+	_, err := MakeCallAndCreateRecord(t, redirect, endpointCorrect, "one.vm.info", 42)
+	if err == nil {
+		t.Fatal("No error when redirect leads to a login page.")
+	}
+	if !strings.Contains(err.Error(), "unexpected root tag") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func Test_WithMaxRequestSize(t *testing.T) {
+	called := false
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return newResponse(http.StatusOK, responseInt), nil
+	})
+
+	client := NewClient(endpointCorrect, nil, WithTransport(transport), WithMaxRequestSize(1024))
+	if _, err := client.Call(context.TODO(), "get", strings.Repeat("pancake", 10)); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	called = false
+	res, err := client.Call(context.TODO(), "get", strings.Repeat("pancake", 100), []int{1, 2, 3})
+	if err == nil {
+		t.Fatal("No error when payload is oversized.")
+	}
+	if !strings.Contains(err.Error(), "exceeds maximum request size 1024") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when payload is oversized.")
+	}
+	if called {
+		t.Fatal("Oversized payload was sent.")
+	}
+}
+
+func Test_WithRequestIDKey(t *testing.T) {
+	var logged []string
+	LogDebugFunc = func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+	defer func() { LogDebugFunc = nil }()
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, responseInt), nil
+	})
+
+	client := NewClient(endpointCorrect, nil, WithTransport(transport))
+	ctx := context.WithValue(context.TODO(), RequestIDKey, "pancake-42")
+	if _, err := client.Call(ctx, "get"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(logged) == 0 || !strings.Contains(logged[0], "request 'pancake-42'") {
+		t.Fatal("Request ID is missing in debug log:", logged)
+	}
+
+	type customKey struct{}
+	logged = nil
+	client = NewClient(endpointCorrect, nil, WithTransport(transport), WithRequestIDKey(customKey{}))
+	ctx = context.WithValue(context.TODO(), customKey{}, 7)
+	if _, err := client.Call(ctx, "get"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(logged) == 0 || !strings.Contains(logged[0], "request '7'") {
+		t.Fatal("Request ID under custom key is missing in debug log:", logged)
+	}
+}
+
+func Test_WithResponseValidator(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, responseInt), nil
+	})
+
+	var validated []byte
+	client := NewClient(endpointCorrect, nil, WithTransport(transport), WithResponseValidator(func(body []byte) error {
+		validated = body
+		return nil
+	}))
+	if _, err := client.Call(context.TODO(), "get"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if string(validated) != responseInt {
+		t.Fatal("Validator didn't receive raw response:", string(validated))
+	}
+
+	client = NewClient(endpointCorrect, nil, WithTransport(transport), WithResponseValidator(func(body []byte) error {
+		return errors.New("schema mismatch")
+	}))
+	res, err := client.Call(context.TODO(), "get")
+	if err == nil {
+		t.Fatal("No error when validator rejects response.")
+	}
+	if !strings.Contains(err.Error(), "response validation failed: schema mismatch") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when validator rejects response.")
+	}
+}
+
+func Test_WithBaseContext(t *testing.T) {
+	started := make(chan struct{})
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		close(started)
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	base, cancel := context.WithCancel(context.Background())
+	client := NewClient(endpointCorrect, nil, WithTransport(transport), WithBaseContext(base))
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Call(context.TODO(), "get")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("No error when base context is cancelled.")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Call isn't aborted by cancelled base context.")
+	}
+}
+
+func Test_WithBaseContext_perCallContext(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, responseInt), nil
+	})
+
+	base, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := NewClient(endpointCorrect, nil, WithTransport(transport), WithBaseContext(base))
+
+	ctx, stop := context.WithTimeout(context.Background(), time.Minute)
+	defer stop()
+	if _, err := client.Call(ctx, "get"); err != nil {
+		t.Fatal("Per-call context is replaced by base context:", err)
+	}
+}
@@ -1,8 +1,18 @@
 package xmlrpc
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/beevik/etree"
@@ -25,8 +35,32 @@ const (
 	KindStruct
 )
 
-const methodResponseValuePath = "methodResponse/params/param/value"
-const methodResponseFaultPath = "methodResponse/fault"
+var kindNames = [...]string{
+	KindInvalid:  "KindInvalid",
+	KindArray:    "KindArray",
+	KindBase64:   "KindBase64",
+	KindBool:     "KindBool",
+	KindDateTime: "KindDateTime",
+	KindDouble:   "KindDouble",
+	KindInt:      "KindInt",
+	KindString:   "KindString",
+	KindStruct:   "KindStruct",
+}
+
+// String returns the name of the kind constant, e.g. 'KindInt', unknown kinds are formatted as 'Kind(n)'
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+
+	return fmt.Sprintf("Kind(%d)", uint(k))
+}
+
+const methodResponseTag = "methodResponse"
+const methodResponseValuePath = "params/param/value"
+const methodResponseFaultPath = "fault"
+const methodResponseParamsTag = "params"
+const methodResponseParamTag = "param"
 const arrayValuePath = "data/value"
 const faultMembersPath = "value/struct/member"
 const faultCodeName = "faultCode"
@@ -39,6 +73,14 @@ const structMemberPath = "member"
 const structMemberNameTag = "name"
 const structMemberValueTag = "value"
 
+const defaultMaxDepth = 100
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+var gzipMagic = []byte{0x1F, 0x8B}
+
+// faultElement matches the 'fault' element of the response including an optional namespace prefix
+var faultElement = regexp.MustCompile(`(?s)<(?:[\w.-]+:)?fault[\s>].*</(?:[\w.-]+:)?fault\s*>`)
+
 // Result represents a return value from XML-RPC method call
 type Result struct {
 	resString   string
@@ -50,59 +92,243 @@ type Result struct {
 	resStruct   map[string]*Result
 	resArray    []*Result
 	kind        Kind
+	stats       map[Kind]int
+	document    *etree.Document
+}
+
+// parser holds settings and state of XML-RPC response parsing
+type parser struct {
+	trimStrings    bool
+	allowEmpty     bool
+	lenientInts    bool
+	lenientDoubles bool
+	maxDepth       int
+	depth          int
+	maxElements    int
+	elements       int
+	maxBase64      int
+	decimalSep     string
+	maxGzip        int
+
+	collectStats bool
+	stats        map[Kind]int
+	keepDocument bool
+
+	errorCodeKey    string
+	errorMessageKey string
+	faultErrors     map[int]error
+}
+
+func (p *parser) parseResult(data []byte) (*Result, error) {
+	results, err := p.parseResults(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
 }
 
-func parseResult(data []byte) (*Result, error) {
+// parseResults parses all params of the response, at least one result is returned on success
+func (p *parser) parseResults(data []byte) ([]*Result, error) {
+	if p.allowEmpty && len(bytes.TrimSpace(data)) == 0 {
+		return []*Result{{kind: KindInvalid}}, nil
+	}
+
+	size := len(data)
+	if p.maxGzip > 0 && bytes.HasPrefix(data, gzipMagic) {
+		var err error
+		if data, err = gunzip(data, p.maxGzip); err != nil {
+			return nil, errors.Wrapf(err, "cannot decompress XML RPC response (%d bytes)", size)
+		}
+	}
+
 	doc, err := constructXML(data)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot parse XML RPC response")
+		return nil, errors.Wrapf(err, "cannot parse XML RPC response (%d bytes)", size)
 	}
 
-	result, err := parseResponse(doc)
+	if p.collectStats {
+		p.stats = make(map[Kind]int)
+	}
+
+	results, err := p.parseResponse(doc)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot parse XML RPC response")
+		if fault, ok := err.(*Fault); ok {
+			fault.raw = faultElement.Find(data)
+		}
+		return nil, errors.Wrapf(err, "cannot parse XML RPC response (%d bytes)", size)
 	}
 
-	return result, nil
+	for _, result := range results {
+		result.stats = p.stats
+		if p.keepDocument {
+			result.document = doc
+		}
+	}
+	return results, nil
+}
+
+// leadingZeroDecimal reports whether the integer is written with leading zeros but without a base prefix,
+// e.g. '007', such integers are decimal even if non-standard integers are allowed
+func leadingZeroDecimal(text string) bool {
+	digits := strings.TrimLeft(text, "+-")
+	return len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9'
+}
+
+// gunzip decompresses gzip-compressed data, decompression stops with an error when more than limit bytes
+// would be produced, so a small compressed response cannot expand without bounds
+func gunzip(data []byte, limit int) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	decompressed, err := ioutil.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > limit {
+		return nil, errors.Errorf("decompressed size exceeds maximum size %d", limit)
+	}
+
+	return decompressed, nil
 }
 
 func constructXML(data []byte) (*etree.Document, error) {
 	doc := etree.NewDocument()
-	if err := doc.ReadFromBytes(data); err != nil {
+	doc.ReadSettings.CharsetReader = charsetReader
+	if err := doc.ReadFromBytes(bytes.TrimPrefix(data, utf8BOM)); err != nil {
 		return nil, errors.Wrap(err, "failed to reconstruct XML DOM")
 	}
+	stripNamespaces(&doc.Element)
 
 	return doc, nil
 }
 
-func parseResponse(doc *etree.Document) (*Result, error) {
-	valueTag := doc.FindElement(methodResponseValuePath)
-	faultTag := doc.FindElement(methodResponseFaultPath)
-	if (valueTag == nil && faultTag == nil) || (valueTag != nil && faultTag != nil) {
-		return nil, errors.Errorf("failed to recognize XML RPC response")
+// stripNamespaces removes namespace prefixes of the element and all its descendants, so namespaced responses,
+// e.g. '<ns:methodResponse>', are matched by the paths of unprefixed tags. The tree is walked with an explicit
+// stack, so deeply nested responses can't exhaust the goroutine stack before the depth limit is checked.
+func stripNamespaces(e *etree.Element) {
+	stack := []*etree.Element{e}
+	for len(stack) > 0 {
+		e = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		e.Space = ""
+		stack = append(stack, e.ChildElements()...)
+	}
+}
+
+func (p *parser) parseResponse(doc *etree.Document) ([]*Result, error) {
+	// root lookup skips any whitespace, comments and processing instructions around 'methodResponse'
+	root := doc.Root()
+	if root == nil {
+		return nil, errors.Errorf("failed to recognize XML RPC response: no root tag found")
+	}
+	if root.Tag != methodResponseTag {
+		return nil, errors.Errorf("failed to recognize XML RPC response: unexpected root tag %s", outline(root))
+	}
+
+	valueTags := root.FindElements(methodResponseValuePath)
+	faultTag := root.FindElement(methodResponseFaultPath)
+	if len(valueTags) == 0 && faultTag == nil && emptyParams(root) {
+		return []*Result{{kind: KindInvalid}}, nil
+	}
+	if (len(valueTags) == 0 && faultTag == nil) || (len(valueTags) != 0 && faultTag != nil) {
+		return nil, errors.Errorf("failed to recognize XML RPC response: unexpected structure %s", outline(root))
 	}
 
 	if faultTag != nil {
-		return parseFault(faultTag)
+		_, err := p.parseFault(faultTag)
+		return nil, err
+	}
+
+	results := make([]*Result, len(valueTags))
+	for i, valueTag := range valueTags {
+		result, err := p.parseValue(valueTag)
+		if err != nil {
+			if len(valueTags) > 1 {
+				return nil, errors.Wrapf(err, "param %d", i+1)
+			}
+			return nil, err
+		}
+
+		if fault := p.errorStructFault(result); fault != nil {
+			return nil, fault
+		}
+		results[i] = result
 	}
 
-	return parseValue(valueTag)
+	return results, nil
 }
 
-func parseFault(e *etree.Element) (*Result, error) {
+// emptyParams reports whether the response contains only a 'params' tag without any 'param' tags
+func emptyParams(root *etree.Element) bool {
+	children := root.ChildElements()
+	return len(children) == 1 && children[0].Tag == methodResponseParamsTag &&
+		len(children[0].SelectElements(methodResponseParamTag)) == 0
+}
+
+// errorStructFault converts a struct result containing configured error code and message members to a fault,
+// zero error code is considered a success
+func (p *parser) errorStructFault(result *Result) *Fault {
+	if p.errorCodeKey == "" || result.kind != KindStruct {
+		return nil
+	}
+
+	code, ok := result.resStruct[p.errorCodeKey]
+	if !ok || code == nil || code.kind != KindInt || code.resInt == 0 {
+		return nil
+	}
+	message, ok := result.resStruct[p.errorMessageKey]
+	if !ok || message == nil || message.kind != KindString {
+		return nil
+	}
+
+	return p.newFault(int(code.resInt), message.resString)
+}
+
+// outline describes the tag of an element and tags of its descendants up to two levels deep,
+// e.g. 'methodResponse(params(param))'
+func outline(e *etree.Element) string {
+	return fmt.Sprintf("'%s'", outlineElement(e, 2))
+}
+
+func outlineElement(e *etree.Element, depth int) string {
+	children := e.ChildElements()
+	if len(children) == 0 || depth == 0 {
+		return e.Tag
+	}
+
+	tags := make([]string, len(children))
+	for i, child := range children {
+		tags[i] = outlineElement(child, depth-1)
+	}
+
+	return fmt.Sprintf("%s(%s)", e.Tag, strings.Join(tags, ", "))
+}
+
+func (p *parser) parseFault(e *etree.Element) (*Result, error) {
 	members := e.FindElements(faultMembersPath)
-	if len(members) != 2 {
+	if len(members) != 2 && len(members) != 3 {
 		return nil, errors.Errorf("failed to recognize XML RPC fault")
 	}
 
 	var errCode *etree.Element
 	var errMsg *etree.Element
+	var details []*Result
+	seen := make(map[string]bool, len(members))
 
 	for _, member := range members {
 		name := member.FindElement(faultMemberNameTag)
 		if name == nil {
 			break
 		}
+		if seen[name.Text()] {
+			return nil, errors.Errorf("malformed fault: member '%s' found multiple times", name.Text())
+		}
+		seen[name.Text()] = true
 
 		if name.Text() == faultCodeName {
 			errCode = member.FindElement(faultMemberValueIntPath)
@@ -114,68 +340,135 @@ func parseFault(e *etree.Element) (*Result, error) {
 		if name.Text() == faultStringName {
 			errMsg = member.FindElement(faultMemberValueStringPath)
 		}
+
+		if name.Text() != faultCodeName && name.Text() != faultStringName {
+			var err error
+			if details, err = p.parseFaultDetails(member); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if errCode == nil || errMsg == nil {
 		return nil, errors.Errorf("failed to recognize XML RPC fault")
 	}
 
-	return nil, errors.Errorf("XML RPC error: %s: %s", errCode.Text(), errMsg.Text())
+	code, err := strconv.Atoi(strings.TrimSpace(errCode.Text()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to recognize XML RPC fault")
+	}
+
+	fault := p.newFault(code, errMsg.Text())
+	fault.Details = details
+	return nil, fault
+}
+
+// parseFaultDetails parses the value of an additional fault member, which must be an array
+func (p *parser) parseFaultDetails(member *etree.Element) ([]*Result, error) {
+	value := member.FindElement(structMemberValueTag)
+	if value == nil {
+		return nil, errors.Errorf("failed to recognize XML RPC fault: no 'value' tag found for details")
+	}
+
+	details, err := p.parseValue(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to recognize XML RPC fault details")
+	}
+	if details.kind != KindArray {
+		return nil, errors.Errorf("failed to recognize XML RPC fault: details of kind %s aren't array", details.kind)
+	}
+
+	return details.resArray, nil
+}
+
+// newFault returns a fault wrapping the error its code is mapped to
+func (p *parser) newFault(code int, message string) *Fault {
+	return &Fault{Code: code, Message: message, err: p.faultErrors[code]}
 }
 
-func parseValue(e *etree.Element) (*Result, error) {
+func (p *parser) parseValue(e *etree.Element) (*Result, error) {
 	childElements := e.ChildElements()
 	if len(childElements) != 1 {
 		return nil, errors.Errorf("'value' tag doesn't contain exactly one child tag")
 	}
 
-	return parseElement(childElements[0])
+	return p.parseElement(childElements[0])
+}
+
+func (p *parser) parseElement(e *etree.Element) (*Result, error) {
+	result, err := p.parseTag(e)
+	if err == nil && p.stats != nil {
+		p.stats[result.kind]++
+	}
+
+	return result, err
 }
 
-func parseElement(e *etree.Element) (*Result, error) {
+func (p *parser) parseTag(e *etree.Element) (*Result, error) {
+	text := e.Text()
+	if e.Tag != "string" || p.trimStrings {
+		text = strings.TrimSpace(text)
+	}
+
 	switch e.Tag {
 	case "string":
-		return &Result{resString: e.Text(), kind: KindString}, nil
+		return &Result{resString: text, kind: KindString}, nil
 	case "int":
 		fallthrough
+	case "i8":
+		fallthrough
 	case "i4":
-		number, err := strconv.Atoi(e.Text())
+		base := 10
+		// base 0 would also accept underscore digit separators, which stay invalid
+		if p.lenientInts && !leadingZeroDecimal(text) && !strings.Contains(text, "_") {
+			base = 0
+		}
+		number, err := strconv.ParseInt(text, base, 64)
 		if err != nil {
-			return nil, errors.Wrapf(err, "cannot convert '%s' to integer", e.Text())
+			return nil, errors.Wrapf(err, "cannot convert '%s' to integer", text)
 		}
-		return &Result{resInt: int64(number), kind: KindInt}, nil
+		return &Result{resInt: number, kind: KindInt}, nil
 	case "boolean":
-		boolean, err := strconv.ParseBool(e.Text())
+		boolean, err := strconv.ParseBool(strings.ToLower(text))
 		if err != nil {
-			return nil, errors.Wrapf(err, "cannot convert '%s' to boolean", e.Text())
+			return nil, errors.Wrapf(err, "cannot convert '%s' to boolean", text)
 		}
 		return &Result{resBoolean: boolean, kind: KindBool}, nil
 	case "double":
-		double, err := strconv.ParseFloat(e.Text(), 64)
+		if p.decimalSep != "" {
+			text = strings.Replace(text, p.decimalSep, ".", 1)
+		}
+		if p.lenientDoubles && (strings.HasPrefix(text, "+") || strings.HasPrefix(text, "-")) {
+			text = text[:1] + strings.TrimSpace(text[1:])
+		}
+		double, err := strconv.ParseFloat(text, 64)
 		if err != nil {
-			return nil, errors.Wrapf(err, "cannot convert '%s' to floating point number", e.Text())
+			return nil, errors.Wrapf(err, "cannot convert '%s' to floating point number", text)
 		}
 		return &Result{resDouble: double, kind: KindDouble}, nil
 	case "dateTime.iso8601":
-		time, err := time.Parse(timeFormat, e.Text())
+		time, err := time.Parse(timeFormat, text)
 		if err != nil {
-			return nil, errors.Wrapf(err, "cannot convert '%s' to a date", e.Text())
+			return nil, errors.Wrapf(err, "cannot convert '%s' to a date", text)
 		}
 		return &Result{resDateTime: time, kind: KindDateTime}, nil
 	case "base64":
-		base64, err := base64.StdEncoding.DecodeString(e.Text())
+		if size := decodedSize(text); p.maxBase64 > 0 && size > p.maxBase64 {
+			return nil, errors.Errorf("base64 value of %d bytes exceeds maximum size %d", size, p.maxBase64)
+		}
+		base64, err := base64.StdEncoding.DecodeString(text)
 		if err != nil {
-			return nil, errors.Wrapf(err, "cannot decode '%s' as base64", e.Text())
+			return nil, errors.Wrapf(err, "cannot decode '%s' as base64", text)
 		}
 		return &Result{resBase64: base64, kind: KindBase64}, nil
 	case "array":
-		results, err := parseArray(e)
+		results, err := p.parseArray(e)
 		if err != nil {
 			return nil, err
 		}
 		return &Result{resArray: results, kind: KindArray}, nil
 	case "struct":
-		results, err := parseStruct(e)
+		results, err := p.parseStruct(e)
 		if err != nil {
 			return nil, err
 		}
@@ -185,14 +478,51 @@ func parseElement(e *etree.Element) (*Result, error) {
 	}
 }
 
-func parseArray(e *etree.Element) ([]*Result, error) {
+// decodedSize returns the size of base64 encoded text after decoding, it's exact for unwrapped padded text
+// and an upper bound otherwise
+func decodedSize(text string) int {
+	return base64.StdEncoding.DecodedLen(len(text)) - (len(text) - len(strings.TrimRight(text, "=")))
+}
+
+// enter increases the nesting depth when parsing an array or struct, returned func restores the previous depth
+func (p *parser) enter() (func(), error) {
+	p.depth++
+	if p.maxDepth > 0 && p.depth > p.maxDepth {
+		p.depth--
+		return nil, errors.Errorf("maximum nesting depth %d exceeded", p.maxDepth)
+	}
+
+	return func() { p.depth-- }, nil
+}
+
+// count increases the number of parsed array elements and struct members
+func (p *parser) count() error {
+	p.elements++
+	if p.maxElements > 0 && p.elements > p.maxElements {
+		return errors.Errorf("maximum number of elements %d exceeded", p.maxElements)
+	}
+
+	return nil
+}
+
+func (p *parser) parseArray(e *etree.Element) ([]*Result, error) {
+	leave, err := p.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
 	results := make([]*Result, 0)
 	for _, element := range e.FindElements(arrayValuePath) {
+		if err = p.count(); err != nil {
+			return nil, err
+		}
+
 		childElements := element.ChildElements()
 		if len(childElements) != 1 {
 			return nil, errors.Errorf("'value' tag doesn't contain exactly one child tag")
 		}
-		value, err := parseElement(childElements[0])
+		value, err := p.parseElement(childElements[0])
 		if err != nil {
 			return nil, err
 		}
@@ -206,9 +536,19 @@ func parseArray(e *etree.Element) ([]*Result, error) {
 	return results, nil
 }
 
-func parseStruct(e *etree.Element) (map[string]*Result, error) {
+func (p *parser) parseStruct(e *etree.Element) (map[string]*Result, error) {
+	leave, err := p.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
 	results := make(map[string]*Result)
 	for _, member := range e.FindElements(structMemberPath) {
+		if err = p.count(); err != nil {
+			return nil, err
+		}
+
 		name := member.FindElement(structMemberNameTag)
 		value := member.FindElement(structMemberValueTag)
 		if name == nil {
@@ -225,7 +565,7 @@ func parseStruct(e *etree.Element) (map[string]*Result, error) {
 		if len(childElements) != 1 {
 			return nil, errors.Errorf("'value' tag doesn't contain exactly one child tag")
 		}
-		ret, err := parseElement(childElements[0])
+		ret, err := p.parseElement(childElements[0])
 		if err != nil {
 			return nil, err
 		}
@@ -283,3 +623,452 @@ func (r *Result) ResultArray() []*Result {
 func (r *Result) Kind() Kind {
 	return r.kind
 }
+
+// Equal reports whether the result is deeply equal to other result, including nested arrays and structs.
+// Two nil results are equal, nil result is never equal to a non-nil one.
+func (r *Result) Equal(other *Result) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.kind != other.kind {
+		return false
+	}
+
+	switch r.kind {
+	case KindArray:
+		if len(r.resArray) != len(other.resArray) {
+			return false
+		}
+		for i := range r.resArray {
+			if !r.resArray[i].Equal(other.resArray[i]) {
+				return false
+			}
+		}
+		return true
+	case KindBase64:
+		return bytes.Equal(r.resBase64, other.resBase64)
+	case KindBool:
+		return r.resBoolean == other.resBoolean
+	case KindDateTime:
+		return r.resDateTime.Equal(other.resDateTime)
+	case KindDouble:
+		return r.resDouble == other.resDouble
+	case KindInt:
+		return r.resInt == other.resInt
+	case KindString:
+		return r.resString == other.resString
+	case KindStruct:
+		if len(r.resStruct) != len(other.resStruct) {
+			return false
+		}
+		for k, v := range r.resStruct {
+			o, ok := other.resStruct[k]
+			if !ok || !v.Equal(o) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// Clone returns a deep copy of the result which shares no maps, slices or byte buffers with the original
+func (r *Result) Clone() *Result {
+	if r == nil {
+		return nil
+	}
+
+	clone := *r
+	if r.resBase64 != nil {
+		clone.resBase64 = append([]byte(nil), r.resBase64...)
+	}
+	if r.resArray != nil {
+		clone.resArray = make([]*Result, len(r.resArray))
+		for i, v := range r.resArray {
+			clone.resArray[i] = v.Clone()
+		}
+	}
+	if r.resStruct != nil {
+		clone.resStruct = make(map[string]*Result, len(r.resStruct))
+		for k, v := range r.resStruct {
+			clone.resStruct[k] = v.Clone()
+		}
+	}
+	clone.stats = r.Stats()
+	if r.document != nil {
+		clone.document = r.document.Copy()
+	}
+
+	return &clone
+}
+
+// Value returns the result as a plain Go value: int64, string, bool, float64, []byte, time.Time,
+// []interface{} for arrays and map[string]interface{} for structs. Invalid result returns nil.
+func (r *Result) Value() interface{} {
+	if r == nil {
+		return nil
+	}
+
+	switch r.kind {
+	case KindArray:
+		values := make([]interface{}, len(r.resArray))
+		for i, v := range r.resArray {
+			values[i] = v.Value()
+		}
+		return values
+	case KindBase64:
+		return r.resBase64
+	case KindBool:
+		return r.resBoolean
+	case KindDateTime:
+		return r.resDateTime
+	case KindDouble:
+		return r.resDouble
+	case KindInt:
+		return r.resInt
+	case KindString:
+		return r.resString
+	case KindStruct:
+		values := make(map[string]interface{}, len(r.resStruct))
+		for k, v := range r.resStruct {
+			values[k] = v.Value()
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// Len returns the number of elements of an array or members of a struct result, scalar results have length 1
+// and invalid results have length 0
+func (r *Result) Len() int {
+	if r == nil {
+		return 0
+	}
+
+	switch r.kind {
+	case KindArray:
+		return len(r.resArray)
+	case KindStruct:
+		return len(r.resStruct)
+	case KindInvalid:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// Index returns the i-th element of an array result, nil is returned for out of range index or non-array result
+func (r *Result) Index(i int) *Result {
+	if r == nil || r.kind != KindArray || i < 0 || i >= len(r.resArray) {
+		return nil
+	}
+
+	return r.resArray[i]
+}
+
+// Member returns a member of a struct result by its name, the boolean reports whether the member is present
+func (r *Result) Member(name string) (*Result, bool) {
+	if r == nil || r.kind != KindStruct {
+		return nil, false
+	}
+
+	member, ok := r.resStruct[name]
+	return member, ok
+}
+
+// Complex returns a complex number from a struct result with 'real' and 'imag' members of double type
+func (r *Result) Complex() (complex128, error) {
+	if r == nil || r.kind != KindStruct || len(r.resStruct) != 2 {
+		return 0, errors.Errorf("result isn't a struct with exactly two members")
+	}
+
+	re, ok := r.resStruct[complexRealName]
+	if !ok || re == nil || re.kind != KindDouble {
+		return 0, errors.Errorf("no '%s' member of double type found", complexRealName)
+	}
+	im, ok := r.resStruct[complexImagName]
+	if !ok || im == nil || im.kind != KindDouble {
+		return 0, errors.Errorf("no '%s' member of double type found", complexImagName)
+	}
+
+	return complex(re.resDouble, im.resDouble), nil
+}
+
+// BigInt returns a big integer from a result of integer type or of string type containing a decimal number
+func (r *Result) BigInt() (*big.Int, error) {
+	if r == nil {
+		return nil, errors.Errorf("result is nil")
+	}
+
+	switch r.kind {
+	case KindInt:
+		return big.NewInt(r.resInt), nil
+	case KindString:
+		number, ok := new(big.Int).SetString(r.resString, 10)
+		if !ok {
+			return nil, errors.Errorf("cannot convert '%s' to big integer", r.resString)
+		}
+		return number, nil
+	default:
+		return nil, errors.Errorf("result of kind %s cannot be converted to big integer", r.kind)
+	}
+}
+
+// Stats returns the number of values of each kind parsed from the response, including all params
+// and their nested values. It's available only for results returned by calls of a client created with WithParseStats,
+// otherwise nil is returned.
+func (r *Result) Stats() map[Kind]int {
+	if r == nil || r.stats == nil {
+		return nil
+	}
+
+	stats := make(map[Kind]int, len(r.stats))
+	for k, v := range r.stats {
+		stats[k] = v
+	}
+
+	return stats
+}
+
+// maxExactFloatInt is the largest magnitude of integers which are exactly representable by float64
+const maxExactFloatInt = 1 << 53
+
+// Int64 converts a result of integer, double, boolean or string type to an integer, an error is returned
+// if the conversion would lose information
+func (r *Result) Int64() (int64, error) {
+	if r == nil {
+		return 0, errors.Errorf("result is nil")
+	}
+
+	switch r.kind {
+	case KindInt:
+		return r.resInt, nil
+	case KindDouble:
+		if r.resDouble != math.Trunc(r.resDouble) || r.resDouble < math.MinInt64 || r.resDouble >= math.MaxInt64 {
+			return 0, errors.Errorf("cannot convert %g to integer without loss", r.resDouble)
+		}
+		return int64(r.resDouble), nil
+	case KindBool:
+		if r.resBoolean {
+			return 1, nil
+		}
+		return 0, nil
+	case KindString:
+		number, err := strconv.ParseInt(strings.TrimSpace(r.resString), 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "cannot convert '%s' to integer", r.resString)
+		}
+		return number, nil
+	default:
+		return 0, errors.Errorf("result of kind %s cannot be converted to integer", r.kind)
+	}
+}
+
+// Float64 converts a result of double, integer, boolean or string type to a floating point number, an error
+// is returned if the conversion would lose information
+func (r *Result) Float64() (float64, error) {
+	if r == nil {
+		return 0, errors.Errorf("result is nil")
+	}
+
+	switch r.kind {
+	case KindDouble:
+		return r.resDouble, nil
+	case KindInt:
+		if r.resInt > maxExactFloatInt || r.resInt < -maxExactFloatInt {
+			return 0, errors.Errorf("cannot convert %d to floating point number without loss", r.resInt)
+		}
+		return float64(r.resInt), nil
+	case KindBool:
+		if r.resBoolean {
+			return 1, nil
+		}
+		return 0, nil
+	case KindString:
+		number, err := strconv.ParseFloat(strings.TrimSpace(r.resString), 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "cannot convert '%s' to floating point number", r.resString)
+		}
+		return number, nil
+	default:
+		return 0, errors.Errorf("result of kind %s cannot be converted to floating point number", r.kind)
+	}
+}
+
+// Bool converts a result of boolean type, of integer type with value 0 or 1 or of string type with value
+// 'true' or 'false' to a boolean
+func (r *Result) Bool() (bool, error) {
+	if r == nil {
+		return false, errors.Errorf("result is nil")
+	}
+
+	switch r.kind {
+	case KindBool:
+		return r.resBoolean, nil
+	case KindInt:
+		if r.resInt != 0 && r.resInt != 1 {
+			return false, errors.Errorf("cannot convert %d to boolean", r.resInt)
+		}
+		return r.resInt == 1, nil
+	case KindString:
+		switch strings.ToLower(strings.TrimSpace(r.resString)) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		default:
+			return false, errors.Errorf("cannot convert '%s' to boolean", r.resString)
+		}
+	default:
+		return false, errors.Errorf("result of kind %s cannot be converted to boolean", r.kind)
+	}
+}
+
+// Walk calls fn for the result and for all nested array elements and struct members in depth-first order,
+// struct members are visited in the order of their names. Path of the result itself is empty, paths of nested
+// values are built from member names separated by dots and element indexes in brackets, e.g. 'toppings[2].weight'.
+// An error returned by fn aborts the walk and is returned.
+func (r *Result) Walk(fn func(path string, r *Result) error) error {
+	return r.walk("", fn)
+}
+
+func (r *Result) walk(path string, fn func(path string, r *Result) error) error {
+	if err := fn(path, r); err != nil {
+		return err
+	}
+	if r == nil {
+		return nil
+	}
+
+	switch r.kind {
+	case KindArray:
+		for i, v := range r.resArray {
+			if err := v.walk(fmt.Sprintf("%s[%d]", path, i), fn); err != nil {
+				return err
+			}
+		}
+	case KindStruct:
+		names := make([]string, 0, len(r.resStruct))
+		for name := range r.resStruct {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			memberPath := name
+			if path != "" {
+				memberPath = path + "." + name
+			}
+			if err := r.resStruct[name].walk(memberPath, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Flatten returns scalar values of the result nested in arrays and structs keyed by their Walk paths,
+// e.g. 'vm.template.cpu' or 'vm.disks[0].size', values are converted by Value. Empty arrays and structs
+// are omitted and a scalar result is returned under an empty key.
+func (r *Result) Flatten() map[string]interface{} {
+	values := make(map[string]interface{})
+	_ = r.Walk(func(path string, r *Result) error {
+		if r == nil || (r.kind != KindArray && r.kind != KindStruct) {
+			values[path] = r.Value()
+		}
+		return nil
+	})
+
+	return values
+}
+
+// Document returns the parsed XML document of the response for custom queries, it's available only for results
+// returned by calls of a client created with WithDocument, otherwise nil is returned
+func (r *Result) Document() *etree.Document {
+	if r == nil {
+		return nil
+	}
+
+	return r.document
+}
+
+// NewIntResult returns a result of integer type, e.g. for building responses
+func NewIntResult(data int64) *Result {
+	return &Result{resInt: data, kind: KindInt}
+}
+
+// NewStringResult returns a result of string type
+func NewStringResult(data string) *Result {
+	return &Result{resString: data, kind: KindString}
+}
+
+// NewBoolResult returns a result of boolean type
+func NewBoolResult(data bool) *Result {
+	return &Result{resBoolean: data, kind: KindBool}
+}
+
+// NewDoubleResult returns a result of double type
+func NewDoubleResult(data float64) *Result {
+	return &Result{resDouble: data, kind: KindDouble}
+}
+
+// NewDateTimeResult returns a result of type representing date and time
+func NewDateTimeResult(data time.Time) *Result {
+	return &Result{resDateTime: data, kind: KindDateTime}
+}
+
+// NewBase64Result returns a result of base64 type
+func NewBase64Result(data []byte) *Result {
+	return &Result{resBase64: data, kind: KindBase64}
+}
+
+// NewArrayResult returns a result of array type containing the elements
+func NewArrayResult(elements ...*Result) *Result {
+	return &Result{resArray: append([]*Result{}, elements...), kind: KindArray}
+}
+
+// NewStructResult returns a result of struct type containing the members
+func NewStructResult(members map[string]*Result) *Result {
+	resStruct := make(map[string]*Result, len(members))
+	for k, v := range members {
+		resStruct[k] = v
+	}
+
+	return &Result{resStruct: resStruct, kind: KindStruct}
+}
+
+// StructSlice returns members of all structs of an array result, an error is returned if the result isn't
+// an array or any of its elements isn't a struct
+func (r *Result) StructSlice() ([]map[string]*Result, error) {
+	if r == nil || r.kind != KindArray {
+		return nil, errors.Errorf("result isn't an array")
+	}
+
+	structs := make([]map[string]*Result, len(r.resArray))
+	for i, element := range r.resArray {
+		if element == nil || element.kind != KindStruct {
+			return nil, errors.Errorf("element %d isn't a struct", i)
+		}
+		structs[i] = element.resStruct
+	}
+
+	return structs, nil
+}
+
+// InterfaceSlice returns elements of an array result converted by Value, so elements of mixed kinds are allowed,
+// an error is returned if the result isn't an array
+func (r *Result) InterfaceSlice() ([]interface{}, error) {
+	if r == nil || r.kind != KindArray {
+		return nil, errors.Errorf("result isn't an array")
+	}
+
+	values := make([]interface{}, len(r.resArray))
+	for i, element := range r.resArray {
+		values[i] = element.Value()
+	}
+
+	return values, nil
+}
@@ -1,8 +1,19 @@
 package xmlrpc
 
 import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/pkg/errors"
 )
 
 const endpointXML = "http://127.0.0.1:8000/file.xml"
@@ -30,10 +41,41 @@ const (
 	parseFaultError   = "records/parse_fault"
 	parseFaultName    = "records/parse_fault_name"
 	parseFaultMembers = "records/parse_fault_members"
+	parseFaultDetails = "records/parse_fault_details"
+	parseFaultDup     = "records/parse_fault_duplicate"
+
+	parseBOM        = "records/parse_bom"
+	parseComment    = "records/parse_comment"
+	parseWhitespace = "records/parse_whitespace"
+	parseBoolText   = "records/parse_boolean_text"
+	parseLatin1     = "records/parse_latin1"
+	parseErrStruct  = "records/parse_error_struct"
+	wrongRootTag    = "records/wrong_root_tag"
+	parseEmpty      = "records/parse_empty"
+	parseEmptyParam = "records/parse_empty_params"
+	parseNamespaces = "records/parse_namespaces"
+	parseExponent   = "records/parse_double_exponent"
+	parseComma      = "records/parse_double_comma"
+	parseLeadZero   = "records/parse_int_leading_zero"
+	parsePadded     = "records/parse_double_padded"
+	parseNegZero    = "records/parse_int_negative_zero"
 )
 
+func Test_wrongXMLFormat_size(t *testing.T) {
+	_, err := MakeCallAndCreateRecord(t, wrongXMLFormat, endpointXML, "get")
+	expected := fmt.Sprintf("cannot parse XML RPC response (%d bytes)", len(recordedBody(t, wrongXMLFormat)))
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Fatal("Error doesn't contain response size:", err)
+	}
+
+	_, err = NewDecoder().DecodeResponse([]byte("<methodResponse>"))
+	if err == nil || !strings.Contains(err.Error(), "cannot parse XML RPC response (16 bytes)") {
+		t.Fatal("Error doesn't contain response size:", err)
+	}
+}
+
 func Test_wrongXMLFormat(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, wrongXMLFormat, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, wrongXMLFormat, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -46,7 +88,7 @@ func Test_wrongXMLFormat(t *testing.T) {
 }
 
 func Test_wrongXMLResponse(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, wrongXMLResponse, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, wrongXMLResponse, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -59,7 +101,7 @@ func Test_wrongXMLResponse(t *testing.T) {
 }
 
 func Test_wrongValueTag(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, wrongValueTag, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, wrongValueTag, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -72,7 +114,7 @@ func Test_wrongValueTag(t *testing.T) {
 }
 
 func Test_parseError_int(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorInt, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorInt, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -85,7 +127,7 @@ func Test_parseError_int(t *testing.T) {
 }
 
 func Test_parseError_double(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorDouble, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorDouble, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -98,7 +140,7 @@ func Test_parseError_double(t *testing.T) {
 }
 
 func Test_parseError_time(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorTime, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorTime, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -111,7 +153,7 @@ func Test_parseError_time(t *testing.T) {
 }
 
 func Test_parseError_array(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorArray, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorArray, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -124,7 +166,7 @@ func Test_parseError_array(t *testing.T) {
 }
 
 func Test_parseError_array_element(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorArrayElement, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorArrayElement, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -137,7 +179,7 @@ func Test_parseError_array_element(t *testing.T) {
 }
 
 func Test_parseError_base64(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorBase64, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorBase64, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -150,7 +192,7 @@ func Test_parseError_base64(t *testing.T) {
 }
 
 func Test_parseError_boolean(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorBoolean, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorBoolean, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -163,7 +205,7 @@ func Test_parseError_boolean(t *testing.T) {
 }
 
 func Test_parseError_oneChildTag(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorOnechildtag, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorOnechildtag, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -176,7 +218,7 @@ func Test_parseError_oneChildTag(t *testing.T) {
 }
 
 func Test_parseError_struct_noName(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorStructNoname, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorStructNoname, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -189,7 +231,7 @@ func Test_parseError_struct_noName(t *testing.T) {
 }
 
 func Test_parseError_struct_noValue(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorStructNovalue, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorStructNovalue, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -202,7 +244,7 @@ func Test_parseError_struct_noValue(t *testing.T) {
 }
 
 func Test_parseError_struct_noMember(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorStructNomember, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorStructNomember, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -215,7 +257,7 @@ func Test_parseError_struct_noMember(t *testing.T) {
 }
 
 func Test_parseError_struct_element(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorStructElement, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorStructElement, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -228,7 +270,7 @@ func Test_parseError_struct_element(t *testing.T) {
 }
 
 func Test_parseError_struct_multipleMembers(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorStructMultipleMembers, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorStructMultipleMembers, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -241,7 +283,7 @@ func Test_parseError_struct_multipleMembers(t *testing.T) {
 }
 
 func Test_parseError_struct_oneChildTag(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorStructOnechildtag, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorStructOnechildtag, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -254,7 +296,7 @@ func Test_parseError_struct_oneChildTag(t *testing.T) {
 }
 
 func Test_parseError_wrongTag(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseErrorWrongtag, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseErrorWrongtag, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -267,7 +309,7 @@ func Test_parseError_wrongTag(t *testing.T) {
 }
 
 func Test_parseFault(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseFaultError, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseFaultError, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -279,8 +321,45 @@ func Test_parseFault(t *testing.T) {
 	}
 }
 
+func Test_parseFault_details(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseFaultDetails, endpointXML, "get")
+	if err == nil {
+		t.Fatal("No error when server returns fault.")
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when server returns fault.")
+	}
+
+	fault, ok := errors.Cause(err).(*Fault)
+	if !ok {
+		t.Fatal("Error isn't caused by fault:", err)
+	}
+	if fault.Code != 4096 || fault.Message != "[one.vm.allocate] Wrong template." {
+		t.Fatal("Unexpected fault:", fault)
+	}
+	if len(fault.Details) != 2 || fault.Details[0].ResultString() != "CPU" || fault.Details[1].ResultInt() != 42 {
+		t.Fatal("Unexpected fault details:", fault.Details)
+	}
+}
+
+func Test_parseFault_duplicateCode(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseFaultDup, endpointXML, "get")
+	if err == nil {
+		t.Fatal("No error when fault contains duplicate members.")
+	}
+	if !strings.Contains(err.Error(), "malformed fault: member 'faultCode' found multiple times") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, ok := errors.Cause(err).(*Fault); ok {
+		t.Fatal("Malformed fault is returned as fault:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when fault is malformed.")
+	}
+}
+
 func Test_parseFault_nameNil(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseFaultName, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseFaultName, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -293,7 +372,7 @@ func Test_parseFault_nameNil(t *testing.T) {
 }
 
 func Test_parseFault_members(t *testing.T) {
-	res, err := MakeCallAndCreateRecord(t, parseFaultMembers, endpointXML, "")
+	res, err := MakeCallAndCreateRecord(t, parseFaultMembers, endpointXML, "get")
 	if err == nil {
 		t.Fatal("No error when parse wrong XML response.")
 	}
@@ -304,3 +383,975 @@ func Test_parseFault_members(t *testing.T) {
 		t.Fatal("Method Call returns result when parse wrong XML response.")
 	}
 }
+
+func nestedResult() *Result {
+	return &Result{kind: KindStruct, resStruct: map[string]*Result{
+		"name":  {kind: KindString, resString: "pancake"},
+		"blob":  {kind: KindBase64, resBase64: []byte("maple syrup")},
+		"baked": {kind: KindDateTime, resDateTime: time.Date(2018, 8, 1, 20, 31, 0, 0, time.UTC)},
+		"toppings": {kind: KindArray, resArray: []*Result{
+			{kind: KindInt, resInt: 42},
+			{kind: KindBool, resBoolean: true},
+			{kind: KindStruct, resStruct: map[string]*Result{
+				"weight": {kind: KindDouble, resDouble: 1.5},
+			}},
+		}},
+	}}
+}
+
+func Test_Result_Equal(t *testing.T) {
+	if !nestedResult().Equal(nestedResult()) {
+		t.Fatal("Equal results are not equal.")
+	}
+
+	var nilResult *Result
+	if !nilResult.Equal(nil) {
+		t.Fatal("Nil results are not equal.")
+	}
+	if nilResult.Equal(nestedResult()) || nestedResult().Equal(nil) {
+		t.Fatal("Nil result is equal to non-nil result.")
+	}
+}
+
+func Test_Result_Equal_different(t *testing.T) {
+	other := nestedResult()
+	other.resStruct["toppings"].resArray[2].resStruct["weight"].resDouble = 2.5
+	if nestedResult().Equal(other) {
+		t.Fatal("Results with different nested double are equal.")
+	}
+
+	other = nestedResult()
+	other.resStruct["blob"].resBase64 = []byte("honey")
+	if nestedResult().Equal(other) {
+		t.Fatal("Results with different base64 are equal.")
+	}
+
+	other = nestedResult()
+	other.resStruct["toppings"].resArray = other.resStruct["toppings"].resArray[:2]
+	if nestedResult().Equal(other) {
+		t.Fatal("Results with different array length are equal.")
+	}
+
+	other = nestedResult()
+	delete(other.resStruct, "name")
+	other.resStruct["title"] = &Result{kind: KindString, resString: "pancake"}
+	if nestedResult().Equal(other) {
+		t.Fatal("Results with different struct members are equal.")
+	}
+
+	if (&Result{kind: KindInt, resInt: 1}).Equal(&Result{kind: KindBool, resBoolean: true}) {
+		t.Fatal("Results of different kinds are equal.")
+	}
+}
+
+func Test_Result_Clone(t *testing.T) {
+	original := nestedResult()
+	clone := original.Clone()
+	if !original.Equal(clone) {
+		t.Fatal("Clone is not equal to the original.")
+	}
+
+	clone.resStruct["name"] = &Result{kind: KindString, resString: "waffle"}
+	delete(clone.resStruct, "baked")
+	clone.resStruct["blob"].resBase64[0] = 'M'
+	clone.resStruct["toppings"].resArray[0].resInt = 7
+	if !original.Equal(nestedResult()) {
+		t.Fatal("Modifying the clone changed the original.")
+	}
+
+	var nilResult *Result
+	if nilResult.Clone() != nil {
+		t.Fatal("Clone of nil result is not nil.")
+	}
+}
+
+func Test_Result_Value(t *testing.T) {
+	value, ok := nestedResult().Value().(map[string]interface{})
+	if !ok {
+		t.Fatal("Struct result isn't converted to map[string]interface{}.")
+	}
+	if _, ok := value["name"].(string); !ok {
+		t.Fatal("String result isn't converted to string.")
+	}
+	if _, ok := value["blob"].([]byte); !ok {
+		t.Fatal("Base64 result isn't converted to []byte.")
+	}
+	if _, ok := value["baked"].(time.Time); !ok {
+		t.Fatal("DateTime result isn't converted to time.Time.")
+	}
+
+	toppings, ok := value["toppings"].([]interface{})
+	if !ok {
+		t.Fatal("Array result isn't converted to []interface{}.")
+	}
+	if _, ok := toppings[0].(int64); !ok {
+		t.Fatal("Int result isn't converted to int64.")
+	}
+	if _, ok := toppings[1].(bool); !ok {
+		t.Fatal("Boolean result isn't converted to bool.")
+	}
+
+	weight, ok := toppings[2].(map[string]interface{})
+	if !ok {
+		t.Fatal("Nested struct result isn't converted to map[string]interface{}.")
+	}
+	if weight["weight"] != 1.5 {
+		t.Fatal("Double result isn't converted to float64.")
+	}
+
+	if (&Result{}).Value() != nil {
+		t.Fatal("Invalid result isn't converted to nil.")
+	}
+}
+
+func Test_parse_bom(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseBOM, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Kind() != KindString || res.ResultString() != "pancake" {
+		t.Fatal("Method Call returns wrong result.")
+	}
+}
+
+func Test_parse_comment(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseComment, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Kind() != KindInt || res.ResultInt() != 42 {
+		t.Fatal("Method Call returns wrong result.")
+	}
+}
+
+func Test_parse_whitespace(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseWhitespace, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	members := res.ResultStruct()
+	if members["int"].ResultInt() != 42 || members["i4"].ResultInt() != -7 {
+		t.Fatal("Method Call returns wrong integer result.")
+	}
+	if members["double"].ResultDouble() != 1.5 {
+		t.Fatal("Method Call returns wrong double result.")
+	}
+	if !members["boolean"].ResultBoolean() {
+		t.Fatal("Method Call returns wrong boolean result.")
+	}
+	if members["dateTime"].ResultDateTime().Year() != 1995 {
+		t.Fatal("Method Call returns wrong dateTime result.")
+	}
+	if members["string"].ResultString() != "  pancake  " {
+		t.Fatal("Method Call trims string result.")
+	}
+}
+
+func Test_parse_whitespace_trimStrings(t *testing.T) {
+	res, err := MakeCallWithOptionsAndCreateRecord(t, parseWhitespace, endpointXML, []Option{WithTrimStrings()}, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultStruct()["string"].ResultString() != "pancake" {
+		t.Fatal("Method Call doesn't trim string result.")
+	}
+}
+
+func Test_parse_booleanText(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseBoolText, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := []bool{true, false, true, false}
+	if len(res.ResultArray()) != len(expected) {
+		t.Fatal("Method Call returns wrong result.")
+	}
+	for i, e := range expected {
+		if res.ResultArray()[i].Kind() != KindBool || res.ResultArray()[i].ResultBoolean() != e {
+			t.Fatal("Method Call returns wrong result at index", i)
+		}
+	}
+}
+
+func Test_Result_Len(t *testing.T) {
+	res := nestedResult()
+	if res.Len() != 4 {
+		t.Fatal("Wrong length of struct result:", res.Len())
+	}
+	if res.ResultStruct()["toppings"].Len() != 3 {
+		t.Fatal("Wrong length of array result:", res.ResultStruct()["toppings"].Len())
+	}
+	if res.ResultStruct()["name"].Len() != 1 {
+		t.Fatal("Wrong length of scalar result:", res.ResultStruct()["name"].Len())
+	}
+
+	var nilResult *Result
+	if (&Result{}).Len() != 0 || nilResult.Len() != 0 {
+		t.Fatal("Wrong length of invalid result.")
+	}
+}
+
+func Test_Result_Index(t *testing.T) {
+	toppings := nestedResult().ResultStruct()["toppings"]
+	if toppings.Index(0).ResultInt() != 42 || !toppings.Index(1).ResultBoolean() {
+		t.Fatal("Index returns wrong element.")
+	}
+	if toppings.Index(-1) != nil || toppings.Index(3) != nil {
+		t.Fatal("Index returns element for out of range index.")
+	}
+	if nestedResult().Index(0) != nil || nestedResult().ResultStruct()["name"].Index(0) != nil {
+		t.Fatal("Index returns element for non-array result.")
+	}
+}
+
+func Test_Result_Member(t *testing.T) {
+	res := nestedResult()
+	res.resStruct["nothing"] = nil
+
+	member, ok := res.Member("name")
+	if !ok || member.ResultString() != "pancake" {
+		t.Fatal("Member returns wrong present member.")
+	}
+
+	member, ok = res.Member("nothing")
+	if !ok || member != nil {
+		t.Fatal("Member doesn't report present nil member.")
+	}
+
+	member, ok = res.Member("waffle")
+	if ok || member != nil {
+		t.Fatal("Member reports absent member as present.")
+	}
+
+	if _, ok = res.ResultStruct()["toppings"].Member("name"); ok {
+		t.Fatal("Member reports member of non-struct result as present.")
+	}
+}
+
+func Test_Result_Complex_wrongResult(t *testing.T) {
+	if _, err := nestedResult().Complex(); err == nil {
+		t.Fatal("No error when struct result isn't a complex number.")
+	}
+	if _, err := (&Result{kind: KindDouble, resDouble: 1.5}).Complex(); err == nil {
+		t.Fatal("No error when result isn't a struct.")
+	}
+
+	res := &Result{kind: KindStruct, resStruct: map[string]*Result{
+		"real": {kind: KindDouble, resDouble: 1.5},
+		"imag": {kind: KindString, resString: "2.25"},
+	}}
+	if _, err := res.Complex(); err == nil {
+		t.Fatal("No error when imaginary part isn't a double.")
+	}
+}
+
+func Test_Result_BigInt(t *testing.T) {
+	res, err := (&parser{}).parseResult([]byte(`<?xml version="1.0"?><methodResponse><params><param><value><array>` +
+		`<data><value><string>-123456789012345678901234567890</string></value><value><i8>9000000000</i8></value>` +
+		`<value><string>pancake</string></value></data></array></value></param></params></methodResponse>`))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	number, err := res.Index(0).BigInt()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if number.String() != "-123456789012345678901234567890" {
+		t.Fatal("Wrong big integer:", number)
+	}
+
+	number, err = res.Index(1).BigInt()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if number.Cmp(big.NewInt(9000000000)) != 0 {
+		t.Fatal("Wrong big integer:", number)
+	}
+
+	if _, err = res.Index(2).BigInt(); err == nil {
+		t.Fatal("No error when string isn't a number.")
+	}
+	if _, err = res.BigInt(); err == nil {
+		t.Fatal("No error when result is an array.")
+	}
+}
+
+func Test_parse_latin1(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseLatin1, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultString() != "Crème brûlée à la maïs" {
+		t.Fatal("Method Call returns wrong result:", res.ResultString())
+	}
+}
+
+func Test_charsetReader_unsupported(t *testing.T) {
+	_, err := (&parser{}).parseResult([]byte(`<?xml version="1.0" encoding="KOI8-R"?><methodResponse><params>` +
+		`<param><value><string>pancake</string></value></param></params></methodResponse>`))
+	if err == nil {
+		t.Fatal("No error when response charset is unsupported.")
+	}
+	if !strings.Contains(err.Error(), "unsupported charset 'KOI8-R'") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func nestedArrayResponse(depth int) string {
+	return `<?xml version="1.0"?><methodResponse><params><param><value>` +
+		strings.Repeat("<array><data><value>", depth) + "<int>42</int>" + strings.Repeat("</value></data></array>", depth) +
+		`</value></param></params></methodResponse>`
+}
+
+func Test_parse_maxDepth(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, nestedArrayResponse(4)), nil
+	})
+
+	client := NewClient(endpointXML, nil, WithTransport(transport), WithMaxDepth(4))
+	if _, err := client.Call(context.TODO(), "get"); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	client = NewClient(endpointXML, nil, WithTransport(transport), WithMaxDepth(3))
+	res, err := client.Call(context.TODO(), "get")
+	if err == nil {
+		t.Fatal("No error when response is nested beyond the limit.")
+	}
+	if !strings.Contains(err.Error(), "maximum nesting depth 3 exceeded") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when response is nested beyond the limit.")
+	}
+}
+
+func Test_parse_maxDepth_default(t *testing.T) {
+	p := parser{maxDepth: defaultMaxDepth}
+	if _, err := p.parseResult([]byte(nestedArrayResponse(defaultMaxDepth))); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if _, err := p.parseResult([]byte(nestedArrayResponse(defaultMaxDepth + 1))); err == nil {
+		t.Fatal("No error when response is nested beyond the default limit.")
+	}
+}
+
+func Test_parse_maxElements(t *testing.T) {
+	response := `<?xml version="1.0"?><methodResponse><params><param><value><array><data>` +
+		strings.Repeat("<value><int>42</int></value>", 3) +
+		`<value><struct><member><name>donut</name><value><int>10</int></value></member></struct></value>` +
+		`</data></array></value></param></params></methodResponse>`
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, response), nil
+	})
+
+	client := NewClient(endpointXML, nil, WithTransport(transport), WithMaxElements(5))
+	if _, err := client.Call(context.TODO(), "get"); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	client = NewClient(endpointXML, nil, WithTransport(transport), WithMaxElements(4))
+	res, err := client.Call(context.TODO(), "get")
+	if err == nil {
+		t.Fatal("No error when response contains too many elements.")
+	}
+	if !strings.Contains(err.Error(), "maximum number of elements 4 exceeded") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when response contains too many elements.")
+	}
+}
+
+func Test_parse_errorStruct(t *testing.T) {
+	res, err := MakeCallWithOptionsAndCreateRecord(t, parseErrStruct, endpointXML,
+		[]Option{WithErrorStruct("errcode", "errmsg")}, "get")
+	if err == nil {
+		t.Fatal("No error when response is an error struct.")
+	}
+	fault, ok := errors.Cause(err).(*Fault)
+	if !ok {
+		t.Fatal("Unexpected error:", err)
+	}
+	if fault.Code != 404 || fault.Message != "Pancake not found." {
+		t.Fatal("Wrong fault:", fault)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when response is an error struct.")
+	}
+}
+
+func Test_parse_errorStruct_disabled(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseErrStruct, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Kind() != KindStruct {
+		t.Fatal("Method Call returns wrong result.")
+	}
+}
+
+func Test_wrongRootTag(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, wrongRootTag, endpointXML, "get")
+	if err == nil {
+		t.Fatal("No error when parse wrong XML response.")
+	}
+	if !strings.Contains(err.Error(), "unexpected root tag 'html(head(title), body(h1))'") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when parse wrong XML response.")
+	}
+}
+
+func Test_wrongXMLResponse_structure(t *testing.T) {
+	_, err := MakeCallAndCreateRecord(t, wrongXMLResponse, endpointXML, "get")
+	if err == nil {
+		t.Fatal("No error when parse wrong XML response.")
+	}
+	if !strings.Contains(err.Error(), "unexpected structure 'methodResponse(param(value))'") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func Test_Result_Stats(t *testing.T) {
+	p := parser{collectStats: true}
+	res, err := p.parseResult([]byte(`<?xml version="1.0"?><methodResponse><params><param><value><struct>` +
+		`<member><name>name</name><value><string>pancake</string></value></member>` +
+		`<member><name>toppings</name><value><array><data><value><int>42</int></value><value><i4>7</i4></value>` +
+		`<value><boolean>1</boolean></value><value><struct><member><name>weight</name><value><double>1.5</double>` +
+		`</value></member></struct></value></data></array></value></member>` +
+		`</struct></value></param></params></methodResponse>`))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := map[Kind]int{KindStruct: 2, KindString: 1, KindArray: 1, KindInt: 2, KindBool: 1, KindDouble: 1}
+	if !reflect.DeepEqual(res.Stats(), expected) {
+		t.Fatal("Unexpected stats:", res.Stats())
+	}
+	if !reflect.DeepEqual(res.Clone().Stats(), expected) {
+		t.Fatal("Stats aren't cloned:", res.Clone().Stats())
+	}
+
+	res, err = (&parser{}).parseResult([]byte(responseInt))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Stats() != nil {
+		t.Fatal("Stats are collected when disabled.")
+	}
+}
+
+func Test_Result_Int64(t *testing.T) {
+	tests := []struct {
+		result   *Result
+		expected int64
+	}{
+		{&Result{kind: KindInt, resInt: -42}, -42},
+		{&Result{kind: KindDouble, resDouble: 42}, 42},
+		{&Result{kind: KindDouble, resDouble: -1e15}, -1e15},
+		{&Result{kind: KindBool, resBoolean: true}, 1},
+		{&Result{kind: KindBool}, 0},
+		{&Result{kind: KindString, resString: " 9000000000 "}, 9000000000},
+	}
+	for _, test := range tests {
+		number, err := test.result.Int64()
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if number != test.expected {
+			t.Fatal("Unexpected integer:", number)
+		}
+	}
+
+	lossy := []*Result{
+		nil,
+		{kind: KindDouble, resDouble: 1.5},
+		{kind: KindDouble, resDouble: 1e19},
+		{kind: KindDouble, resDouble: math.NaN()},
+		{kind: KindString, resString: "1.0"},
+		{kind: KindArray, resArray: []*Result{{kind: KindInt}}},
+	}
+	for _, result := range lossy {
+		if _, err := result.Int64(); err == nil {
+			t.Fatal("No error when result cannot be converted to integer:", result)
+		}
+	}
+}
+
+func Test_Result_Float64(t *testing.T) {
+	tests := []struct {
+		result   *Result
+		expected float64
+	}{
+		{&Result{kind: KindDouble, resDouble: 1.5}, 1.5},
+		{&Result{kind: KindInt, resInt: -42}, -42},
+		{&Result{kind: KindInt, resInt: 1 << 53}, 1 << 53},
+		{&Result{kind: KindBool, resBoolean: true}, 1},
+		{&Result{kind: KindString, resString: "2.5e3"}, 2500},
+	}
+	for _, test := range tests {
+		number, err := test.result.Float64()
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if number != test.expected {
+			t.Fatal("Unexpected floating point number:", number)
+		}
+	}
+
+	lossy := []*Result{
+		nil,
+		{kind: KindInt, resInt: 1<<53 + 1},
+		{kind: KindInt, resInt: math.MinInt64},
+		{kind: KindString, resString: "pancake"},
+		{kind: KindStruct, resStruct: map[string]*Result{"weight": {kind: KindDouble}}},
+	}
+	for _, result := range lossy {
+		if _, err := result.Float64(); err == nil {
+			t.Fatal("No error when result cannot be converted to floating point number:", result)
+		}
+	}
+}
+
+func Test_Result_Bool(t *testing.T) {
+	tests := []struct {
+		result   *Result
+		expected bool
+	}{
+		{&Result{kind: KindBool, resBoolean: true}, true},
+		{&Result{kind: KindBool}, false},
+		{&Result{kind: KindInt, resInt: 1}, true},
+		{&Result{kind: KindInt}, false},
+		{&Result{kind: KindString, resString: "true"}, true},
+		{&Result{kind: KindString, resString: "False"}, false},
+	}
+	for _, test := range tests {
+		boolean, err := test.result.Bool()
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if boolean != test.expected {
+			t.Fatal("Unexpected boolean:", boolean)
+		}
+	}
+
+	invalid := []*Result{
+		nil,
+		{kind: KindInt, resInt: 2},
+		{kind: KindString, resString: "yes"},
+		{kind: KindDouble, resDouble: 1},
+	}
+	for _, result := range invalid {
+		if _, err := result.Bool(); err == nil {
+			t.Fatal("No error when result cannot be converted to boolean:", result)
+		}
+	}
+}
+
+func Test_parse_empty(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseEmpty, endpointXML, "get")
+	if err == nil {
+		t.Fatal("No error when parse empty response.")
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when parse empty response.")
+	}
+
+	res, err = MakeCallWithOptionsAndCreateRecord(t, parseEmpty, endpointXML, []Option{WithAllowEmptyResponse()}, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Kind() != KindInvalid {
+		t.Fatal("Method Call returns wrong result kind for empty response:", res.Kind())
+	}
+}
+
+func Test_Kind_String(t *testing.T) {
+	tests := map[Kind]string{
+		KindInvalid:  "KindInvalid",
+		KindArray:    "KindArray",
+		KindBase64:   "KindBase64",
+		KindBool:     "KindBool",
+		KindDateTime: "KindDateTime",
+		KindDouble:   "KindDouble",
+		KindInt:      "KindInt",
+		KindString:   "KindString",
+		KindStruct:   "KindStruct",
+		Kind(42):     "Kind(42)",
+	}
+	for kind, expected := range tests {
+		if kind.String() != expected {
+			t.Fatal("Unexpected kind name:", kind.String(), "expected:", expected)
+		}
+	}
+	if fmt.Sprint(KindInt) != "KindInt" {
+		t.Fatal("Kind isn't formatted by its name:", fmt.Sprint(KindInt))
+	}
+}
+
+func Test_Result_Walk(t *testing.T) {
+	var paths []string
+	err := nestedResult().Walk(func(path string, r *Result) error {
+		paths = append(paths, path+":"+r.Kind().String())
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := []string{
+		":KindStruct",
+		"baked:KindDateTime",
+		"blob:KindBase64",
+		"name:KindString",
+		"toppings:KindArray",
+		"toppings[0]:KindInt",
+		"toppings[1]:KindBool",
+		"toppings[2]:KindStruct",
+		"toppings[2].weight:KindDouble",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatal("Unexpected paths:", paths)
+	}
+}
+
+func Test_Result_Walk_abort(t *testing.T) {
+	var visited int
+	err := nestedResult().Walk(func(path string, r *Result) error {
+		visited++
+		if path == "toppings[1]" {
+			return errors.New("stop")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "stop" {
+		t.Fatal("Walk doesn't return error of visitor:", err)
+	}
+	if visited != 7 {
+		t.Fatal("Walk isn't aborted, visited:", visited)
+	}
+}
+
+func Test_Result_Flatten(t *testing.T) {
+	res := &Result{kind: KindStruct, resStruct: map[string]*Result{
+		"vm": {kind: KindStruct, resStruct: map[string]*Result{
+			"template": {kind: KindStruct, resStruct: map[string]*Result{
+				"cpu": {kind: KindDouble, resDouble: 0.5},
+			}},
+			"disks": {kind: KindArray, resArray: []*Result{
+				{kind: KindStruct, resStruct: map[string]*Result{"size": {kind: KindInt, resInt: 1024}}},
+				{kind: KindStruct, resStruct: map[string]*Result{"size": {kind: KindInt, resInt: 2048}}},
+			}},
+			"nics": {kind: KindArray},
+		}},
+		"name": {kind: KindString, resString: "pancake"},
+	}}
+
+	expected := map[string]interface{}{
+		"vm.template.cpu":  0.5,
+		"vm.disks[0].size": int64(1024),
+		"vm.disks[1].size": int64(2048),
+		"name":             "pancake",
+	}
+	if flat := res.Flatten(); !reflect.DeepEqual(flat, expected) {
+		t.Fatal("Unexpected flattened result:", flat)
+	}
+
+	flat := (&Result{kind: KindInt, resInt: 42}).Flatten()
+	if !reflect.DeepEqual(flat, map[string]interface{}{"": int64(42)}) {
+		t.Fatal("Unexpected flattened scalar result:", flat)
+	}
+}
+
+func Test_WithMaxBase64Size(t *testing.T) {
+	blob := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("maple syrup", 100)))
+	response := []byte(`<?xml version="1.0"?><methodResponse><params><param><value><base64>` + blob +
+		`</base64></value></param></params></methodResponse>`)
+
+	p := parser{maxBase64: 1100}
+	res, err := p.parseResult(response)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(res.ResultBase64()) != 1100 {
+		t.Fatal("Unexpected base64 result size:", len(res.ResultBase64()))
+	}
+
+	p = parser{maxBase64: 1099}
+	_, err = p.parseResult(response)
+	if err == nil {
+		t.Fatal("No error when base64 value exceeds maximum size.")
+	}
+	if !strings.Contains(err.Error(), "base64 value of 1100 bytes exceeds maximum size 1099") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func Test_Result_Document(t *testing.T) {
+	res, err := MakeCallWithOptionsAndCreateRecord(t, parseWhitespace, endpointXML, []Option{WithDocument()}, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Document() == nil {
+		t.Fatal("Document isn't retained.")
+	}
+
+	i4 := res.Document().FindElement("//member[name='i4']/value/i4")
+	if i4 == nil || strings.TrimSpace(i4.Text()) != "-7" {
+		t.Fatal("Query on retained document failed.")
+	}
+	if res.Clone().Document() == res.Document() {
+		t.Fatal("Cloned result shares document.")
+	}
+
+	res, err = MakeCallAndCreateRecord(t, parseWhitespace, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Document() != nil {
+		t.Fatal("Document is retained when disabled.")
+	}
+}
+
+func Test_parse_emptyParams(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseEmptyParam, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Kind() != KindInvalid {
+		t.Fatal("Method Call returns wrong result kind for empty params:", res.Kind())
+	}
+}
+
+func Test_WithLenientIntegers(t *testing.T) {
+	tests := map[string]int64{
+		"+42":      42,
+		"0x2A":     42,
+		"-0x2a":    -42,
+		"0o52":     42,
+		"0b101010": 42,
+	}
+
+	for text, expected := range tests {
+		response := []byte(`<?xml version="1.0"?><methodResponse><params><param><value><int>` + text +
+			`</int></value></param></params></methodResponse>`)
+
+		res, err := NewDecoder(WithLenientIntegers()).DecodeResponse(response)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if res.ResultInt() != expected {
+			t.Fatal("Unexpected integer parsed from", text, ":", res.ResultInt())
+		}
+
+		_, err = NewDecoder().DecodeResponse(response)
+		if text == "+42" && err != nil {
+			t.Fatal("Error:", err)
+		}
+		if text != "+42" && err == nil {
+			t.Fatal("No error when integer has prefix in strict mode:", text)
+		}
+	}
+}
+
+func Test_WithLenientIntegers_underscores(t *testing.T) {
+	for _, text := range []string{"1_000", "0x_1F", "0b1_0"} {
+		response := []byte(`<?xml version="1.0"?><methodResponse><params><param><value><int>` + text +
+			`</int></value></param></params></methodResponse>`)
+		if _, err := NewDecoder(WithLenientIntegers()).DecodeResponse(response); err == nil {
+			t.Fatal("No error when integer contains underscore:", text)
+		}
+	}
+}
+
+func Test_parse_namespaces(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseNamespaces, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	members := res.ResultStruct()
+	if members["id"].ResultInt() != 42 || members["name"].ResultString() != "pancake" {
+		t.Fatal("Method Call returns wrong result for namespaced response.")
+	}
+	if res.Len() != 2 {
+		t.Fatal("Method Call returns wrong number of members:", res.Len())
+	}
+}
+
+func Test_stripNamespaces_deep(t *testing.T) {
+	doc := etree.NewDocument()
+	e := &doc.Element
+	for i := 0; i < 100000; i++ {
+		e = e.CreateElement("ns:value")
+	}
+
+	stripNamespaces(&doc.Element)
+	if e.Space != "" || doc.Root().Space != "" {
+		t.Fatal("Namespace prefix isn't removed from nested elements.")
+	}
+}
+
+func Test_Result_StructSlice(t *testing.T) {
+	pool := NewArrayResult(
+		NewStructResult(map[string]*Result{"ID": NewIntResult(0), "NAME": NewStringResult("pancake")}),
+		NewStructResult(map[string]*Result{"ID": NewIntResult(1), "NAME": NewStringResult("waffle")}),
+	)
+
+	structs, err := pool.StructSlice()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(structs) != 2 || structs[0]["NAME"].ResultString() != "pancake" || structs[1]["ID"].ResultInt() != 1 {
+		t.Fatal("Unexpected structs:", structs)
+	}
+
+	empty, err := NewArrayResult().StructSlice()
+	if err != nil || len(empty) != 0 {
+		t.Fatal("Unexpected structs of empty array:", empty, err)
+	}
+
+	if _, err = NewStructResult(nil).StructSlice(); err == nil {
+		t.Fatal("No error when result isn't an array.")
+	}
+	_, err = NewArrayResult(NewStructResult(nil), NewIntResult(1)).StructSlice()
+	if err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Fatal("Unexpected error when element isn't a struct:", err)
+	}
+}
+
+func Test_Result_InterfaceSlice(t *testing.T) {
+	mixed := NewArrayResult(
+		NewIntResult(42),
+		NewStringResult("pancake"),
+		NewStructResult(map[string]*Result{"NAME": NewStringResult("waffle")}),
+	)
+
+	values, err := mixed.InterfaceSlice()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	expected := []interface{}{int64(42), "pancake", map[string]interface{}{"NAME": "waffle"}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatal("Unexpected values:", values)
+	}
+
+	if _, err = NewIntResult(42).InterfaceSlice(); err == nil {
+		t.Fatal("No error when result isn't an array.")
+	}
+}
+
+func Test_parse_doubleExponent(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseExponent, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := []float64{1500, -0.025, 1e10, 0.5}
+	if len(res.ResultArray()) != len(expected) {
+		t.Fatal("Method Call returns wrong number of doubles.")
+	}
+	for i, r := range res.ResultArray() {
+		if r.ResultDouble() != expected[i] {
+			t.Fatal("Method Call returns wrong double result:", i, r.ResultDouble())
+		}
+	}
+}
+
+func Test_parse_doubleComma(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseComma, endpointXML, "get")
+	if err == nil {
+		t.Fatal("No error when double has comma separator.")
+	}
+	if !strings.Contains(err.Error(), "cannot convert '1,5' to floating point number") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when double has comma separator.")
+	}
+
+	res, err = MakeCallWithOptionsAndCreateRecord(t, parseComma, endpointXML, []Option{WithDecimalSeparator(",")}, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := []float64{1.5, -2.25, 1500, 42}
+	if len(res.ResultArray()) != len(expected) {
+		t.Fatal("Method Call returns wrong number of doubles.")
+	}
+	for i, r := range res.ResultArray() {
+		if r.ResultDouble() != expected[i] {
+			t.Fatal("Method Call returns wrong double result:", i, r.ResultDouble())
+		}
+	}
+}
+
+func Test_parse_intLeadingZero(t *testing.T) {
+	expected := []int64{7, -7, 42, 10, 5}
+	for _, opts := range [][]Option{nil, {WithLenientIntegers()}} {
+		res, err := MakeCallWithOptionsAndCreateRecord(t, parseLeadZero, endpointXML, opts, "get")
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+
+		var numbers []int64
+		if err = res.Scan(&numbers); err != nil {
+			t.Fatal("Error:", err)
+		}
+		if !reflect.DeepEqual(numbers, expected) {
+			t.Fatal("Method Call returns wrong integers:", numbers)
+		}
+	}
+}
+
+func Test_parse_intNegativeZero(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parseNegZero, endpointXML, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	for i, r := range res.ResultArray() {
+		if r.Kind() != KindInt || r.ResultInt() != 0 || r.Value() != int64(0) {
+			t.Fatal("Method Call returns wrong zero:", i, r.Value())
+		}
+		if !r.Equal(NewIntResult(0)) {
+			t.Fatal("Negative zero isn't equal to zero:", i)
+		}
+	}
+
+	value, err := NewEncoder().EncodeValue(res.ResultArray()[0])
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if string(value) != "<value><int>0</int></value>" {
+		t.Fatal("Negative zero is encoded as:", string(value))
+	}
+}
+
+func Test_parse_doublePadded(t *testing.T) {
+	res, err := MakeCallAndCreateRecord(t, parsePadded, endpointXML, "get")
+	if err == nil || !strings.Contains(err.Error(), "cannot convert '+ 1.5' to floating point number") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when double has space after sign.")
+	}
+
+	res, err = MakeCallWithOptionsAndCreateRecord(t, parsePadded, endpointXML, []Option{WithLenientDoubles()}, "get")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	var doubles []float64
+	if err = res.Scan(&doubles); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !reflect.DeepEqual(doubles, []float64{1.5, -2.25, 3, 45}) {
+		t.Fatal("Method Call returns wrong doubles:", doubles)
+	}
+}
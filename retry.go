@@ -0,0 +1,143 @@
+package xmlrpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// retryPolicy holds settings of retries of failed requests
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// statusError is returned when the server responds with a non-2xx HTTP status code, it wraps the error
+// of the status class, e.g. ErrRedirectStatus
+type statusError struct {
+	code       int
+	retryAfter time.Duration
+	location   string
+}
+
+func (e *statusError) Error() string {
+	class := e.Unwrap()
+	if class == nil {
+		return fmt.Sprintf("response error: code %d", e.code)
+	}
+	if e.location != "" {
+		return fmt.Sprintf("%s: code %d to '%s'", class, e.code, e.location)
+	}
+
+	return fmt.Sprintf("%s: code %d", class, e.code)
+}
+
+// Unwrap returns the error of the status class, or nil for codes out of the known classes
+func (e *statusError) Unwrap() error {
+	switch e.code / 100 {
+	case 1:
+		return ErrInformationalStatus
+	case 3:
+		return ErrRedirectStatus
+	case 4:
+		return ErrClientStatus
+	case 5:
+		return ErrServerStatus
+	default:
+		return nil
+	}
+}
+
+func newStatusError(res *http.Response) *statusError {
+	return &statusError{
+		code:       res.StatusCode,
+		retryAfter: parseRetryAfter(res.Header.Get("Retry-After")),
+		location:   res.Header.Get("Location"),
+	}
+}
+
+// parseRetryAfter parses the value of Retry-After header which is either a number of seconds or an HTTP date,
+// zero is returned when the value is missing or invalid
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+	if wait := time.Until(date); wait > 0 {
+		return wait
+	}
+
+	return 0
+}
+
+// retryable returns true if the request which failed with err may succeed when it's sent again
+func retryable(err error) bool {
+	if se, ok := errors.Cause(err).(*statusError); ok {
+		return se.code == http.StatusTooManyRequests || se.code == http.StatusServiceUnavailable
+	}
+
+	return IsTemporary(err)
+}
+
+// delay returns the time to wait before the next attempt, the exponential backoff is randomized by jitter
+// and extended to the wait requested by the server in Retry-After header
+func (p retryPolicy) delay(attempt int, err error) time.Duration {
+	var wait time.Duration
+	if p.backoff > 0 {
+		backoff := p.backoff
+		for i := 1; i < attempt && backoff <= math.MaxInt64>>1; i++ {
+			backoff <<= 1
+		}
+		wait = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	}
+
+	if se, ok := errors.Cause(err).(*statusError); ok && se.retryAfter > wait {
+		wait = se.retryAfter
+	}
+
+	return wait
+}
+
+// makeRequestWithRetry sends the payload and retries failed requests according to the retry policy,
+// waiting is cut short by the context and no attempt is made when the wait would exceed the context deadline
+func (c *Client) makeRequestWithRetry(ctx context.Context, payload []byte) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		res, err := c.roundTrip(ctx, payload)
+		if err == nil || attempt >= c.retry.attempts || ctx.Err() != nil || !retryable(err) {
+			return res, err
+		}
+
+		wait := c.retry.delay(attempt, err)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return nil, errors.Wrapf(err, "attempt %d failed and retry after %s would exceed context deadline",
+				attempt, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Wrapf(ctx.Err(), "waiting for attempt %d failed", attempt+1)
+		case <-timer.C:
+		}
+	}
+}
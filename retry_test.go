@@ -0,0 +1,143 @@
+package xmlrpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const retryAfter = "records/retry_after"
+
+func Test_WithRetry_retryAfterRecord(t *testing.T) {
+	client, stop := CreateClientWithRecorder(t, retryAfter, endpointCorrect, WithRetry(2, time.Millisecond))
+	defer stop()
+
+	start := time.Now()
+	res, err := client.Call(context.TODO(), "one.vm.info", 42)
+	if err == nil {
+		t.Fatal("No error when server is unavailable.")
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when server is unavailable.")
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatal("Retry-After header isn't honored, retried after", elapsed)
+	}
+}
+
+func Test_WithRetry(t *testing.T) {
+	var calls int
+	var bodies []string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		body := new(strings.Builder)
+		if _, err := io.Copy(body, req.Body); err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, body.String())
+
+		if calls == 1 {
+			res := newResponse(http.StatusServiceUnavailable, "")
+			res.Header.Set("Retry-After", "1")
+			return res, nil
+		}
+		if calls == 2 {
+			return newResponse(http.StatusTooManyRequests, ""), nil
+		}
+		return newResponse(http.StatusOK, responseInt), nil
+	})
+
+	client := NewClient(endpointCorrect, nil, WithTransport(transport), WithRetry(3, 10*time.Millisecond))
+	start := time.Now()
+	res, err := client.Call(context.TODO(), "get", 1)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultInt() != 42 {
+		t.Fatal("Unexpected result:", res.ResultInt())
+	}
+	if calls != 3 {
+		t.Fatal("Unexpected number of attempts:", calls)
+	}
+	if bodies[0] == "" || bodies[0] != bodies[2] {
+		t.Fatal("Payload isn't resent on retry.")
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatal("Retry-After header isn't honored, finished after", elapsed)
+	}
+}
+
+func Test_WithRetry_notRetryable(t *testing.T) {
+	var calls int
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return newResponse(http.StatusInternalServerError, ""), nil
+	})
+
+	client := NewClient(endpointCorrect, nil, WithTransport(transport), WithRetry(3, time.Millisecond))
+	if _, err := client.Call(context.TODO(), "get"); err == nil {
+		t.Fatal("No error when server fails.")
+	}
+	if calls != 1 {
+		t.Fatal("Request failed with code 500 is retried.")
+	}
+}
+
+func Test_WithRetry_contextDeadline(t *testing.T) {
+	var calls int
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		res := newResponse(http.StatusServiceUnavailable, "")
+		res.Header.Set("Retry-After", "30")
+		return res, nil
+	})
+
+	client := NewClient(endpointCorrect, nil, WithTransport(transport), WithRetry(5, time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.TODO(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Call(ctx, "get")
+	if err == nil {
+		t.Fatal("No error when retry exceeds context deadline.")
+	}
+	if !strings.Contains(err.Error(), "would exceed context deadline") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatal("Retry wait isn't capped by context deadline, finished after", elapsed)
+	}
+	if calls != 1 {
+		t.Fatal("Unexpected number of attempts:", calls)
+	}
+}
+
+func Test_retryPolicy_delay(t *testing.T) {
+	policy := retryPolicy{attempts: 100, backoff: time.Second}
+	previous := time.Duration(0)
+	for attempt := 1; attempt <= 100; attempt++ {
+		wait := policy.delay(attempt, nil)
+		if wait <= 0 {
+			t.Fatal("Non-positive delay of attempt", attempt, wait)
+		}
+		if attempt > 40 && wait < previous/2 {
+			t.Fatal("Delay drops after backoff overflow at attempt", attempt, wait)
+		}
+		previous = wait
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	if wait := parseRetryAfter("120"); wait != 2*time.Minute {
+		t.Fatal("Unexpected wait:", wait)
+	}
+	if wait := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); wait < 59*time.Minute {
+		t.Fatal("Unexpected wait:", wait)
+	}
+	if wait := parseRetryAfter("soon"); wait != 0 {
+		t.Fatal("Unexpected wait:", wait)
+	}
+}
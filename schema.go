@@ -0,0 +1,80 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Schema describes the expected structure of a result. Zero Kind accepts any kind, Elements describes all
+// elements of an array and Members describes members of a struct, members not listed there aren't checked.
+type Schema struct {
+	Kind     Kind
+	Elements *Schema
+	Members  map[string]*Schema
+	// Optional allows the struct member described by the schema to be missing
+	Optional bool
+}
+
+// Validate checks the result matches the schema, the returned error contains the path of the first mismatching
+// value in the format used by Walk, e.g. 'toppings[2].weight'
+func (r *Result) Validate(s *Schema) error {
+	return r.validate("", s)
+}
+
+func (r *Result) validate(path string, s *Schema) error {
+	if s == nil {
+		return nil
+	}
+	if r == nil {
+		return errors.Errorf("%s: result is nil", describePath(path))
+	}
+	if s.Kind != KindInvalid && r.kind != s.Kind {
+		return errors.Errorf("%s: expected %s, got %s", describePath(path), s.Kind, r.kind)
+	}
+
+	if s.Elements != nil && r.kind == KindArray {
+		for i, element := range r.resArray {
+			if err := element.validate(fmt.Sprintf("%s[%d]", path, i), s.Elements); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(s.Members) > 0 && r.kind == KindStruct {
+		names := make([]string, 0, len(s.Members))
+		for name := range s.Members {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			memberPath := name
+			if path != "" {
+				memberPath = path + "." + name
+			}
+
+			member, ok := r.resStruct[name]
+			if !ok {
+				if s.Members[name] == nil || s.Members[name].Optional {
+					continue
+				}
+				return errors.Errorf("%s: member is missing", describePath(memberPath))
+			}
+			if err := member.validate(memberPath, s.Members[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return "result"
+	}
+
+	return fmt.Sprintf("'%s'", path)
+}
@@ -0,0 +1,55 @@
+package xmlrpc
+
+import (
+	"strings"
+	"testing"
+)
+
+func toppingsSchema() *Schema {
+	return &Schema{Kind: KindStruct, Members: map[string]*Schema{
+		"name":     {Kind: KindString},
+		"blob":     {Kind: KindBase64},
+		"baked":    {},
+		"toppings": {Kind: KindArray, Elements: &Schema{}},
+		"price":    {Kind: KindDouble, Optional: true},
+	}}
+}
+
+func Test_Result_Validate(t *testing.T) {
+	if err := nestedResult().Validate(toppingsSchema()); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	schema := &Schema{Kind: KindArray, Elements: &Schema{Kind: KindStruct, Members: map[string]*Schema{
+		"ID": {Kind: KindInt},
+	}}}
+	pool := NewArrayResult(NewStructResult(map[string]*Result{"ID": NewIntResult(0), "NAME": NewStringResult("a")}))
+	if err := pool.Validate(schema); err != nil {
+		t.Fatal("Error:", err)
+	}
+}
+
+func Test_Result_Validate_mismatch(t *testing.T) {
+	tests := []struct {
+		schema   *Schema
+		expected string
+	}{
+		{&Schema{Kind: KindArray}, "result: expected KindArray, got KindStruct"},
+		{&Schema{Members: map[string]*Schema{"name": {Kind: KindInt}}}, "'name': expected KindInt, got KindString"},
+		{&Schema{Members: map[string]*Schema{"price": {Kind: KindDouble}}}, "'price': member is missing"},
+		{&Schema{Members: map[string]*Schema{"toppings": {Elements: &Schema{Kind: KindInt}}}},
+			"'toppings[1]': expected KindInt, got KindBool"},
+		{&Schema{Members: map[string]*Schema{"toppings": {Elements: &Schema{Members: map[string]*Schema{
+			"weight": {Kind: KindInt}}}}}}, "'toppings[2].weight': expected KindInt, got KindDouble"},
+	}
+
+	for _, test := range tests {
+		err := nestedResult().Validate(test.schema)
+		if err == nil {
+			t.Fatal("No error when result doesn't match schema, expected:", test.expected)
+		}
+		if !strings.Contains(err.Error(), test.expected) {
+			t.Fatal("Unexpected error:", err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package xmlrpc
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// sendStreamed encodes the call directly into the request body, which has unknown length and so it's sent
+// with chunked transfer encoding
+func (c *Client) sendStreamed(ctx context.Context, methodName string, args ...interface{}) ([]byte, error) {
+	encoder := c.encoder
+	payload, err := encoder.buildPayload(methodName, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "payload preparation failed")
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		var w io.Writer = writer
+		if c.maxRequestSize > 0 {
+			w = &limitedWriter{w: writer, limit: c.maxRequestSize}
+		}
+		writer.CloseWithError(encoder.writePayload(w, payload))
+	}()
+
+	res, err := c.guard(ctx, func() ([]byte, error) {
+		return c.makeRequest(ctx, reader)
+	})
+	// unblocks the writer if the request failed before the whole body was sent
+	reader.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "request failed")
+	}
+
+	return res, nil
+}
+
+// limitedWriter fails when more than limit bytes are written
+type limitedWriter struct {
+	w       io.Writer
+	limit   int64
+	written int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.written+int64(len(p)) > l.limit {
+		return 0, errors.Errorf("payload size exceeds maximum request size %d", l.limit)
+	}
+
+	n, err := l.w.Write(p)
+	l.written += int64(n)
+	return n, err
+}
@@ -0,0 +1,78 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_WithStreamingRequests(t *testing.T) {
+	blob := bytes.Repeat([]byte("pancake"), 2*1024*1024)
+	var body []byte
+	var contentLength int64
+	var transferEncoding []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		transferEncoding = r.TransferEncoding
+		var err error
+		if body, err = ioutil.ReadAll(r.Body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		if _, err = io.WriteString(w, responseInt); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, server.Client(), WithStreamingRequests())
+	res, err := client.Call(context.TODO(), "upload", "pancakes.bin", blob)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.ResultInt() != 42 {
+		t.Fatal("Unexpected result:", res.ResultInt())
+	}
+
+	if contentLength != -1 || len(transferEncoding) != 1 || transferEncoding[0] != "chunked" {
+		t.Fatal("Request isn't chunked, content length:", contentLength, "transfer encoding:", transferEncoding)
+	}
+	expected, err := NewEncoder().EncodeCall("upload", "pancakes.bin", blob)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !bytes.Equal(body, expected) {
+		t.Fatal("Streamed payload differs from encoded payload, size:", len(body))
+	}
+	if !strings.Contains(string(body), base64.StdEncoding.EncodeToString(blob)) {
+		t.Fatal("Streamed payload doesn't contain blob.")
+	}
+}
+
+func Test_WithStreamingRequests_maxRequestSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ioutil.ReadAll(r.Body); err != nil {
+			return
+		}
+		if _, err := io.WriteString(w, responseInt); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, server.Client(), WithStreamingRequests(), WithMaxRequestSize(1024))
+	_, err := client.Call(context.TODO(), "upload", bytes.Repeat([]byte("pancake"), 1024))
+	if err == nil {
+		t.Fatal("No error when streamed payload is oversized.")
+	}
+	if !strings.Contains(err.Error(), "exceeds maximum request size 1024") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
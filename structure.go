@@ -3,14 +3,19 @@ package xmlrpc
 import (
 	"encoding/base64"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/beevik/etree"
+	"github.com/pkg/errors"
 )
 
 const xmlInstructionName = "xml"
 const xmlInstruction = `version="1.0" encoding="UTF-8"`
+const xmlDefaultVersion = "1.0"
 
 const enMethodCall = "methodCall"
 const enMethodName = "methodName"
@@ -18,6 +23,7 @@ const enParams = "params"
 const enParam = "param"
 const enValue = "value"
 const enInt = "int"
+const enI8 = "i8"
 const enBoolean = "boolean"
 const enString = "string"
 const enDouble = "double"
@@ -29,6 +35,11 @@ const enStruct = "struct"
 const enArray = "array"
 const enData = "data"
 
+const blobTarget = "xmlrpc-blob"
+
+const complexRealName = "real"
+const complexImagName = "imag"
+
 const timeFormat = "2006-01-02T15:04:05-0700"
 
 type payload struct {
@@ -59,14 +70,24 @@ type scalar struct {
 	*etree.Element
 }
 
+type raw struct {
+	*etree.Element
+}
+
+// Raw represents a pre-encoded XML-RPC value which is inserted into the payload verbatim. It can contain
+// either a whole 'value' element or just the data type element, e.g. '<struct>...</struct>'.
+type Raw []byte
+
 type valueizable interface {
 	toValue() *value
 	etree.Token
 }
 
-func newPayload(methodName string) *payload {
+func newPayload(methodName, declaration string) *payload {
 	p := &payload{etree.NewDocument()}
-	p.CreateProcInst(xmlInstructionName, xmlInstruction)
+	if declaration != "" {
+		p.CreateProcInst(xmlInstructionName, declaration)
+	}
 	elMethodCall := p.CreateElement(enMethodCall)
 	elMethodName := elMethodCall.CreateElement(enMethodName)
 	elMethodName.SetText(methodName)
@@ -75,6 +96,68 @@ func newPayload(methodName string) *payload {
 	return p
 }
 
+// xmlDeclaration returns the content of XML declaration with the given attributes in the standard order,
+// empty version defaults to '1.0', empty encoding and standalone are omitted
+func xmlDeclaration(version, encoding, standalone string) string {
+	if version == "" {
+		version = xmlDefaultVersion
+	}
+
+	declaration := fmt.Sprintf(`version="%s"`, version)
+	if encoding != "" {
+		declaration += fmt.Sprintf(` encoding="%s"`, encoding)
+	}
+	if standalone != "" {
+		declaration += fmt.Sprintf(` standalone="%s"`, standalone)
+	}
+
+	return declaration
+}
+
+func (p *payload) indent(prefix, indent string) {
+	children := p.Child
+	p.Child = nil
+	for _, child := range children {
+		if isWhitespace(child) {
+			continue
+		}
+		if len(p.Child) > 0 {
+			p.CreateCharData("\n" + prefix)
+		}
+		p.AddChild(child)
+		if element, ok := child.(*etree.Element); ok {
+			indentElement(element, "\n"+prefix, indent)
+		}
+	}
+	p.CreateCharData("\n")
+}
+
+// indentElement places every child element of e on a new line, elements containing only text are kept intact
+func indentElement(e *etree.Element, newline, indent string) {
+	if len(e.ChildElements()) == 0 {
+		return
+	}
+
+	children := e.Child
+	e.Child = nil
+	for _, child := range children {
+		if isWhitespace(child) {
+			continue
+		}
+		e.CreateCharData(newline + indent)
+		e.AddChild(child)
+		if element, ok := child.(*etree.Element); ok {
+			indentElement(element, newline+indent, indent)
+		}
+	}
+	e.CreateCharData(newline)
+}
+
+func isWhitespace(t etree.Token) bool {
+	charData, ok := t.(*etree.CharData)
+	return ok && strings.TrimSpace(charData.Data) == ""
+}
+
 func newScalar(typeName string, data string) *scalar {
 	elScalar := &scalar{etree.NewElement(typeName)}
 	elScalar.SetText(data)
@@ -86,7 +169,38 @@ func newInt(data int64) *scalar {
 	return newScalar(enInt, strconv.FormatInt(data, 10))
 }
 
-func newBoolean(data bool) *scalar {
+// newBigInt encodes big integers as 'int', or as 'i8' if enabled, values out of their range are encoded as strings
+func newBigInt(data *big.Int, i8 bool) *scalar {
+	switch {
+	case !data.IsInt64():
+		return newString(data.String())
+	case i8:
+		return newScalar(enI8, data.String())
+	case data.Int64() < math.MinInt32 || data.Int64() > math.MaxInt32:
+		return newString(data.String())
+	default:
+		return newInt(data.Int64())
+	}
+}
+
+// newUint encodes unsigned integers out of the 'int' range as 'i8' if enabled, larger ones cannot be encoded
+func newUint(data uint64, i8 bool) (*scalar, error) {
+	switch {
+	case data <= math.MaxInt32:
+		return newInt(int64(data)), nil
+	case i8 && data <= math.MaxInt64:
+		return newScalar(enI8, strconv.FormatUint(data, 10)), nil
+	case i8:
+		return nil, errors.Errorf("unsigned integer %d overflows 'i8'", data)
+	default:
+		return nil, errors.Errorf("unsigned integer %d overflows 'int', 'i8' can be enabled by WithBigIntAsI8", data)
+	}
+}
+
+func newBoolean(data bool, asText bool) *scalar {
+	if asText {
+		return newScalar(enBoolean, strconv.FormatBool(data))
+	}
 	if data {
 		return newScalar(enBoolean, "1")
 	}
@@ -109,6 +223,69 @@ func newBase64(data []byte) *scalar {
 	return newScalar(enBase64, base64.StdEncoding.EncodeToString(data))
 }
 
+func newRaw(data Raw) (*raw, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, errors.Wrap(err, "raw value is not a well-formed XML")
+	}
+
+	childElements := doc.ChildElements()
+	if len(childElements) != 1 {
+		return nil, errors.Errorf("raw value doesn't contain exactly one root tag")
+	}
+	if hasBlobPlaceholder(childElements[0]) {
+		return nil, errors.Errorf("raw value contains reserved processing instruction '%s'", blobTarget)
+	}
+	doc.RemoveChild(childElements[0])
+
+	return &raw{childElements[0]}, nil
+}
+
+// hasBlobPlaceholder reports whether the element or any of its descendants contains a processing instruction
+// with the target of blob placeholders
+func hasBlobPlaceholder(e *etree.Element) bool {
+	stack := []*etree.Element{e}
+	for len(stack) > 0 {
+		e = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, token := range e.Child {
+			switch t := token.(type) {
+			case *etree.ProcInst:
+				if t.Target == blobTarget {
+					return true
+				}
+			case *etree.Element:
+				stack = append(stack, t)
+			}
+		}
+	}
+
+	return false
+}
+
+// newBase64Placeholder creates a base64 element containing a processing instruction which marks the place
+// of a blob, text content cannot produce the same sequence as '<' is always escaped and raw values containing
+// the same processing instruction are refused
+func newBase64Placeholder(index int) *scalar {
+	elScalar := &scalar{etree.NewElement(enBase64)}
+	elScalar.CreateProcInst(blobTarget, strconv.Itoa(index))
+
+	return elScalar
+}
+
+func blobPlaceholder(index int) string {
+	return fmt.Sprintf("<?%s %d?>", blobTarget, index)
+}
+
+func newComplex(data complex128) *structure {
+	s := newStruct()
+	s.addMember(complexRealName, newDouble(real(data)))
+	s.addMember(complexImagName, newDouble(imag(data)))
+
+	return s
+}
+
 func newStruct() *structure {
 	return &structure{etree.NewElement(enStruct)}
 }
@@ -128,6 +305,17 @@ func (p *payload) addParam(v valueizable) {
 	elParams.AddChild(v.toValue().toParam())
 }
 
+// removeParams removes the 'params' element, the specification allows to omit it for calls without params
+func (p *payload) removeParams() {
+	elMethodCall := p.SelectElement(enMethodCall)
+	if elMethodCall == nil {
+		panic("'methodCall' element not found")
+	}
+	if elParams := elMethodCall.SelectElement(enParams); elParams != nil {
+		elMethodCall.RemoveChild(elParams)
+	}
+}
+
 func wrapToValue(v valueizable) *value {
 	elValue := &value{etree.NewElement(enValue)}
 	elValue.AddChild(v)
@@ -139,6 +327,14 @@ func (s *scalar) toValue() *value {
 	return wrapToValue(s)
 }
 
+func (r *raw) toValue() *value {
+	if r.Tag == enValue {
+		return &value{r.Element}
+	}
+
+	return wrapToValue(r)
+}
+
 func newMember(name string, v valueizable) *member {
 	elMember := &member{etree.NewElement(enMember)}
 	elName := elMember.CreateElement(enName)
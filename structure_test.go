@@ -2,6 +2,10 @@ package xmlrpc
 
 import (
 	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"math"
+	"math/big"
 	"strings"
 	"testing"
 	"time"
@@ -22,10 +26,22 @@ const (
 	argsStruct     = "records/args_struct"
 	argsMap        = "records/args_map"
 	argsKind       = "records/args_kind"
+	argsRaw        = "records/args_raw"
+	argsComplex    = "records/args_complex"
+	argsStructs    = "records/args_struct_slice"
+
+	payloadIndent = "records/payload_indent.xml"
 )
 
 type food struct{}
 
+type dish struct {
+	Name    string `xmlrpc:"name"`
+	Count   int
+	Secret  string `xmlrpc:"-"`
+	private int
+}
+
 func Test_Call_args_int(t *testing.T) {
 	res, err := MakeCallAndCreateRecord(t, argsInt, endpointCorrect, "pow", 2, 9)
 	if err != nil {
@@ -232,6 +248,9 @@ func Test_Call_args_mapNil(t *testing.T) {
 	if !strings.Contains(err.Error(), "payload preparation failed") {
 		t.Fatal("Unexpected error:", err)
 	}
+	if !strings.Contains(err.Error(), "argument 1: member 'ananas': invalid type struct") {
+		t.Fatal("Error doesn't contain member name:", err)
+	}
 	if res != nil {
 		t.Fatal("Method Call returns result when map's value is nil.")
 	}
@@ -246,3 +265,447 @@ func Test_Call_args_kind(t *testing.T) {
 		t.Fatal("Method Call returns wrong result.")
 	}
 }
+
+func Test_Call_args_raw(t *testing.T) {
+	raw := Raw(`<struct><member><name>donut</name><value><int>10</int></value></member>` +
+		`<member><name>steak</name><value><string>medium</string></value></member></struct>`)
+	res, err := MakeCallAndCreateRecord(t, argsRaw, endpointCorrect, "get", raw)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Kind() != KindStruct {
+		t.Fatal("Method Call returns wrong result.")
+	}
+	if res.ResultStruct()["donut"] == nil || res.ResultStruct()["donut"].ResultInt() != 10 {
+		t.Fatal("Method Call returns wrong result.")
+	}
+	if res.ResultStruct()["steak"] == nil || res.ResultStruct()["steak"].ResultString() != "medium" {
+		t.Fatal("Method Call returns wrong result.")
+	}
+}
+
+func Test_Call_args_rawMalformed(t *testing.T) {
+	// test expects fail before connection to the server, no record needed
+	res, err := MakeCallAndCreateRecord(t, "", endpointCorrect, "get", Raw("<struct><member></struct>"))
+	if err == nil {
+		t.Fatal("No error when raw value is not a well-formed XML.")
+	}
+	if !strings.Contains(err.Error(), "payload preparation failed") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when raw value is not a well-formed XML.")
+	}
+}
+
+func Test_BuildPayload_rawBlobPlaceholder(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	client.encoder.base64StreamSize = 4
+	_, err := client.BuildPayload("get", Raw("<string><?xmlrpc-blob 0?></string>"), []byte("pancake"))
+	if err == nil || !strings.Contains(err.Error(), "reserved processing instruction 'xmlrpc-blob'") {
+		t.Fatal("Unexpected error when raw value contains blob placeholder:", err)
+	}
+
+	payload, err := client.BuildPayload("get", Raw("<string><?other 0?></string>"), []byte("pancake"))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<base64>cGFuY2FrZQ==</base64>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+}
+
+func Test_preparePayload_indent(t *testing.T) {
+	golden, err := ioutil.ReadFile(payloadIndent)
+	if err != nil {
+		t.Fatal("Unable to finish test", err)
+	}
+
+	client := NewClient(endpointCorrect, nil, WithIndent("", "  "))
+	payload, err := client.preparePayload("get", map[string][]int{"donut": {1, 2}}, "pancake")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if payload.String() != string(golden) {
+		t.Fatal("Indented payload doesn't match golden file, got:\n", payload.String())
+	}
+}
+
+func Test_preparePayload_compact(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.preparePayload("get", "pancake")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if strings.Contains(payload.String(), "\n") {
+		t.Fatal("Payload isn't compact:", payload.String())
+	}
+}
+
+func Test_preparePayload_xmlDeclaration(t *testing.T) {
+	tests := []struct {
+		opts     []Option
+		expected string
+	}{
+		{nil, `<?xml version="1.0" encoding="UTF-8"?><methodCall>`},
+		{[]Option{WithXMLDeclaration("1.0", "UTF-8", "yes")}, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><methodCall>`},
+		{[]Option{WithXMLDeclaration("1.1", "", "no")}, `<?xml version="1.1" standalone="no"?><methodCall>`},
+		{[]Option{WithXMLDeclaration("", "", "")}, `<?xml version="1.0"?><methodCall>`},
+		{[]Option{WithoutXMLDeclaration()}, `<methodCall>`},
+	}
+
+	for _, test := range tests {
+		client := NewClient(endpointCorrect, nil, test.opts...)
+		payload, err := client.preparePayload("get", "pancake")
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if !strings.HasPrefix(payload.String(), test.expected) {
+			t.Fatal("Unexpected XML declaration:", payload.String())
+		}
+	}
+}
+
+func Test_BuildPayload_emptyParams(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("one.system.version")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.HasSuffix(string(payload), "<methodCall><methodName>one.system.version</methodName><params/></methodCall>") {
+		t.Fatal("Payload doesn't contain empty params:", string(payload))
+	}
+
+	client = NewClient(endpointCorrect, nil, WithoutEmptyParams())
+	payload, err = client.BuildPayload("one.system.version")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.HasSuffix(string(payload), "<methodCall><methodName>one.system.version</methodName></methodCall>") {
+		t.Fatal("Payload contains empty params:", string(payload))
+	}
+
+	payload, err = client.BuildPayload("one.vm.info", 42)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<params><param><value><int>42</int></value></param></params>") {
+		t.Fatal("Payload doesn't contain params:", string(payload))
+	}
+}
+
+func Test_BuildPayload(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	tests := []struct {
+		args     []interface{}
+		expected string
+	}{
+		{[]interface{}{2, 9}, "<params><param><value><int>2</int></value></param>" +
+			"<param><value><int>9</int></value></param></params>"},
+		{[]interface{}{true, "pizza", 1.5}, "<params><param><value><boolean>1</boolean></value></param>" +
+			"<param><value><string>pizza</string></value></param>" +
+			"<param><value><double>1.5</double></value></param></params>"},
+		{[]interface{}{[]interface{}{1, map[string]string{"donut": "glazed"}}}, "<params><param><value><array><data>" +
+			"<value><int>1</int></value>" +
+			"<value><struct><member><name>donut</name><value><string>glazed</string></value></member></struct></value>" +
+			"</data></array></value></param></params>"},
+		{[]interface{}{map[string][]int{"steak": {1, 2}}}, "<params><param><value><struct><member><name>steak</name>" +
+			"<value><array><data><value><int>1</int></value><value><int>2</int></value></data></array></value>" +
+			"</member></struct></value></param></params>"},
+	}
+
+	for _, test := range tests {
+		payload, err := client.BuildPayload("get", test.args...)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		expected := `<?xml version="1.0" encoding="UTF-8"?><methodCall><methodName>get</methodName>` +
+			test.expected + "</methodCall>"
+		if string(payload) != expected {
+			t.Fatal("Unexpected payload:", string(payload))
+		}
+	}
+}
+
+func Test_BuildPayload_invalidArgs(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("get", func() {})
+	if err == nil {
+		t.Fatal("No error when args contain function.")
+	}
+	if !strings.Contains(err.Error(), "payload preparation failed") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if payload != nil {
+		t.Fatal("BuildPayload returns payload when args contain function.")
+	}
+}
+
+func Test_Call_args_complex(t *testing.T) {
+	number := complex(1.5, -2.25)
+	res, err := MakeCallAndCreateRecord(t, argsComplex, endpointCorrect, "get", number)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	decoded, err := res.Complex()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if decoded != number {
+		t.Fatal("Method Call returns wrong result.")
+	}
+}
+
+func Test_BuildPayload_complex64(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("get", complex64(complex(0.5, 4)))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<member><name>real</name><value><double>0.5</double></value></member>"+
+		"<member><name>imag</name><value><double>4</double></value></member>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+}
+
+func Test_BuildPayload_byteSlice(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("get", []byte("hi"), []int8{104, 105})
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><base64>aGk=</base64></value></param>") {
+		t.Fatal("Byte slice isn't encoded as base64:", string(payload))
+	}
+	if !strings.Contains(string(payload), "<param><value><array><data><value><int>104</int></value>"+
+		"<value><int>105</int></value></data></array></value></param>") {
+		t.Fatal("Int8 slice isn't encoded as array:", string(payload))
+	}
+}
+
+func Test_BuildPayload_byteSliceAsArray(t *testing.T) {
+	client := NewClient(endpointCorrect, nil, WithByteSliceAsArray())
+	payload, err := client.BuildPayload("get", []byte("hi"))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><array><data><value><int>104</int></value>"+
+		"<value><int>105</int></value></data></array></value></param>") {
+		t.Fatal("Byte slice isn't encoded as array:", string(payload))
+	}
+}
+
+type blob []byte
+
+func Test_BuildPayload_byteArrayAndNamedSlice(t *testing.T) {
+	args := []interface{}{[2]byte{'h', 'i'}, blob("hi"), struct{ Data blob }{blob("hi")}}
+	payload, err := NewClient(endpointCorrect, nil).BuildPayload("get", args...)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if strings.Count(string(payload), "<base64>aGk=</base64>") != 3 {
+		t.Fatal("Byte array or named byte slice isn't encoded as base64:", string(payload))
+	}
+
+	payload, err = NewClient(endpointCorrect, nil, WithByteSliceAsArray()).BuildPayload("get", args...)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if strings.Count(string(payload), "<array><data><value><int>104</int></value>"+
+		"<value><int>105</int></value></data></array>") != 3 {
+		t.Fatal("Byte array or named byte slice isn't encoded as array:", string(payload))
+	}
+}
+
+func Test_BuildPayload_bigInt(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("get", huge, big.NewInt(-42))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><string>123456789012345678901234567890</string></value></param>"+
+		"<param><value><int>-42</int></value></param>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+
+	payload, err = client.BuildPayload("get", big.NewInt(math.MaxInt32), big.NewInt(math.MaxInt32+1),
+		big.NewInt(math.MinInt32-1))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><int>2147483647</int></value></param>"+
+		"<param><value><string>2147483648</string></value></param>"+
+		"<param><value><string>-2147483649</string></value></param>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+
+	client = NewClient(endpointCorrect, nil, WithBigIntAsI8())
+	payload, err = client.BuildPayload("get", huge, big.NewInt(9000000000))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><string>123456789012345678901234567890</string></value></param>"+
+		"<param><value><i8>9000000000</i8></value></param>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+
+	var nilBigInt *big.Int
+	if _, err = client.BuildPayload("get", nilBigInt); err == nil {
+		t.Fatal("No error when argument is nil *big.Int.")
+	}
+}
+
+func Test_BuildPayload_unsigned(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("get", uint32(math.MaxInt32), uint(7))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><int>2147483647</int></value></param>"+
+		"<param><value><int>7</int></value></param>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+
+	for _, arg := range []interface{}{uint32(math.MaxInt32 + 1), uint(math.MaxInt32 + 1)} {
+		_, err = client.BuildPayload("get", arg)
+		if err == nil || !strings.Contains(err.Error(), "overflows 'int'") {
+			t.Fatalf("Unexpected error for %T out of 'int' range: %v", arg, err)
+		}
+	}
+
+	client = NewClient(endpointCorrect, nil, WithBigIntAsI8())
+	payload, err = client.BuildPayload("get", uint32(math.MaxInt32), uint32(math.MaxInt32+1), uint64(math.MaxInt64))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><int>2147483647</int></value></param>"+
+		"<param><value><i8>2147483648</i8></value></param><param><value><i8>9223372036854775807</i8></value></param>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+
+	_, err = client.BuildPayload("get", uint64(math.MaxInt64+1))
+	if err == nil || !strings.Contains(err.Error(), "overflows 'i8'") {
+		t.Fatal("Unexpected error for unsigned integer out of 'i8' range:", err)
+	}
+}
+
+func Test_BuildPayload_booleanText(t *testing.T) {
+	client := NewClient(endpointCorrect, nil, WithBooleanText())
+	payload, err := client.BuildPayload("get", true, Bool(false))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><boolean>true</boolean></value></param>"+
+		"<param><value><boolean>false</boolean></value></param>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+}
+
+func Test_BuildPayload_duration(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("get", 90*time.Second, []interface{}{1500 * time.Millisecond})
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><int>90</int></value></param>"+
+		"<param><value><array><data><value><int>1</int></value></data></array></value></param>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+
+	client = NewClient(endpointCorrect, nil, WithDurationUnit(time.Millisecond))
+	payload, err = client.BuildPayload("get", 90*time.Second)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<param><value><int>90000</int></value></param>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+}
+
+func Test_Call_args_nestedErrorPath(t *testing.T) {
+	// test expects fail before connection to the server, no record needed
+	args := map[string]interface{}{
+		"toppings": []interface{}{"syrup", map[string]interface{}{"fruit": make(chan int)}},
+	}
+	res, err := MakeCallAndCreateRecord(t, "", endpointCorrect, "get", "pancake", args)
+	if err == nil {
+		t.Fatal("No error when nested value is invalid.")
+	}
+	if !strings.Contains(err.Error(), "argument 2: member 'toppings': element 1: member 'fruit': invalid type chan") {
+		t.Fatal("Error doesn't contain path of invalid value:", err)
+	}
+	if res != nil {
+		t.Fatal("Method Call returns result when nested value is invalid.")
+	}
+}
+
+func Test_Call_args_structSlice(t *testing.T) {
+	dishes := []dish{{Name: "pancake", Count: 3, Secret: "syrup"}, {Name: "waffle", Count: 5}}
+	res, err := MakeCallAndCreateRecord(t, argsStructs, endpointCorrect, "get", dishes)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if res.Kind() != KindArray || len(res.ResultArray()) != len(dishes) {
+		t.Fatal("Method Call returns wrong result.")
+	}
+	for i, d := range dishes {
+		members := res.ResultArray()[i].ResultStruct()
+		if len(members) != 2 || members["name"].ResultString() != d.Name || members["Count"].ResultInt() != int64(d.Count) {
+			t.Fatal("Method Call returns wrong result at index", i)
+		}
+	}
+}
+
+func Test_BuildPayload_struct(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("get", dish{Name: "pancake", Count: 3, Secret: "syrup", private: 1})
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(string(payload), "<struct><member><name>name</name><value><string>pancake</string></value>"+
+		"</member><member><name>Count</name><value><int>3</int></value></member></struct>") {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+}
+
+func Test_BuildPayload_largeBase64(t *testing.T) {
+	blob := bytes.Repeat([]byte("I love pancake."), defaultBase64StreamSize)
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("get", map[string][]byte{"blob": blob}, []byte("small"), blob)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	expected := `<?xml version="1.0" encoding="UTF-8"?><methodCall><methodName>get</methodName><params>` +
+		"<param><value><struct><member><name>blob</name><value><base64>" + encoded + "</base64></value></member>" +
+		"</struct></value></param><param><value><base64>c21hbGw=</base64></value></param>" +
+		"<param><value><base64>" + encoded + "</base64></value></param></params></methodCall>"
+	if string(payload) != expected {
+		t.Fatal("Streamed base64 payload is wrong.")
+	}
+}
+
+func benchmarkPreparePayloadBase64(b *testing.B, streamSize int) {
+	blob := make([]byte, 10*1024*1024)
+	client := NewClient(endpointCorrect, nil)
+	client.encoder.base64StreamSize = streamSize
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.preparePayload("upload", blob); err != nil {
+			b.Fatal("Error:", err)
+		}
+	}
+}
+
+func Benchmark_preparePayload_base64Streamed(b *testing.B) {
+	benchmarkPreparePayloadBase64(b, defaultBase64StreamSize)
+}
+
+func Benchmark_preparePayload_base64String(b *testing.B) {
+	benchmarkPreparePayloadBase64(b, 0)
+}
@@ -0,0 +1,263 @@
+package xmlrpc
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+var resultType = reflect.TypeOf(Result{})
+var resultPtrType = reflect.TypeOf(&Result{})
+
+// scanKinds maps types of scan targets to kinds of results which can be scanned into them
+var scanKinds = map[reflect.Type]Kind{
+	reflect.TypeOf(int64(0)):   KindInt,
+	reflect.TypeOf(""):         KindString,
+	reflect.TypeOf(false):      KindBool,
+	reflect.TypeOf(float64(0)): KindDouble,
+	reflect.TypeOf([]byte{}):   KindBase64,
+	timeType:                   KindDateTime,
+}
+
+// Unmarshal stores the result in the value pointed to by v. Arrays are stored in slices, structs in maps
+// with string keys or in Go structs whose fields are matched by name or by 'xmlrpc' tag, struct members
+// without a matching field are ignored. Base64 is stored in []byte, dateTime in time.Time, any result
+// in an empty interface as returned by Value and a copy of the result in Result or *Result.
+// Numbers and booleans are always converted leniently, the same way as by Int64, Float64 and Bool,
+// e.g. integer 0 or 1 is stored in a bool field. There is no strict mode, use Scan to require exact kinds.
+func (r *Result) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.Errorf("invalid unmarshal target %T, non-nil pointer expected", v)
+	}
+
+	return r.unmarshal(rv.Elem())
+}
+
+func (r *Result) unmarshal(v reflect.Value) error {
+	if r == nil {
+		return errors.Errorf("result is nil")
+	}
+
+	switch v.Type() {
+	case resultPtrType:
+		v.Set(reflect.ValueOf(r.Clone()))
+		return nil
+	case resultType:
+		v.Set(reflect.ValueOf(r.Clone()).Elem())
+		return nil
+	case timeType:
+		if r.kind != KindDateTime {
+			return errors.Errorf("result of kind %s cannot be unmarshaled into time.Time", r.kind)
+		}
+		v.Set(reflect.ValueOf(r.resDateTime))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return errors.Errorf("result cannot be unmarshaled into non-empty interface %s", v.Type())
+		}
+		if value := r.Value(); value != nil {
+			v.Set(reflect.ValueOf(value))
+		}
+		return nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return r.unmarshal(v.Elem())
+	case reflect.Bool:
+		boolean, err := r.Bool()
+		if err != nil {
+			return err
+		}
+		v.SetBool(boolean)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := r.Int64()
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(number) {
+			return errors.Errorf("integer %d overflows %s", number, v.Type())
+		}
+		v.SetInt(number)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := r.Int64()
+		if err != nil {
+			return err
+		}
+		if number < 0 || v.OverflowUint(uint64(number)) {
+			return errors.Errorf("integer %d overflows %s", number, v.Type())
+		}
+		v.SetUint(uint64(number))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		number, err := r.Float64()
+		if err != nil {
+			return err
+		}
+		if v.OverflowFloat(number) {
+			return errors.Errorf("floating point number %g overflows %s", number, v.Type())
+		}
+		v.SetFloat(number)
+		return nil
+	case reflect.String:
+		if r.kind != KindString {
+			return errors.Errorf("result of kind %s cannot be unmarshaled into string", r.kind)
+		}
+		v.SetString(r.resString)
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && r.kind == KindBase64 {
+			v.SetBytes(append([]byte(nil), r.resBase64...))
+			return nil
+		}
+		return r.unmarshalSlice(v)
+	case reflect.Map:
+		return r.unmarshalMap(v)
+	case reflect.Struct:
+		return r.unmarshalStruct(v)
+	default:
+		return errors.Errorf("result cannot be unmarshaled into %s", v.Type())
+	}
+}
+
+func (r *Result) unmarshalSlice(v reflect.Value) error {
+	if r.kind != KindArray {
+		return errors.Errorf("result of kind %s cannot be unmarshaled into %s", r.kind, v.Type())
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(r.resArray), len(r.resArray))
+	for i, element := range r.resArray {
+		if err := element.unmarshal(slice.Index(i)); err != nil {
+			return errors.Wrapf(err, "element %d", i)
+		}
+	}
+	v.Set(slice)
+
+	return nil
+}
+
+func (r *Result) unmarshalMap(v reflect.Value) error {
+	if r.kind != KindStruct {
+		return errors.Errorf("result of kind %s cannot be unmarshaled into %s", r.kind, v.Type())
+	}
+	if v.Type().Key().Kind() != reflect.String {
+		return errors.Errorf("result cannot be unmarshaled into %s, string keys expected", v.Type())
+	}
+
+	m := reflect.MakeMapWithSize(v.Type(), len(r.resStruct))
+	for name, member := range r.resStruct {
+		value := reflect.New(v.Type().Elem()).Elem()
+		if err := member.unmarshal(value); err != nil {
+			return errors.Wrapf(err, "member '%s'", name)
+		}
+		m.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), value)
+	}
+	v.Set(m)
+
+	return nil
+}
+
+func (r *Result) unmarshalStruct(v reflect.Value) error {
+	if r.kind != KindStruct {
+		return errors.Errorf("result of kind %s cannot be unmarshaled into %s", r.kind, v.Type())
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		name, ok := memberName(v.Type().Field(i))
+		if !ok {
+			continue
+		}
+
+		member, ok := r.resStruct[name]
+		if !ok {
+			continue
+		}
+		if err := member.unmarshal(v.Field(i)); err != nil {
+			return errors.Wrapf(err, "member '%s'", name)
+		}
+	}
+
+	return nil
+}
+
+// DecodeInto is an alias of Unmarshal and the primary entry point for decoding results, every target is decoded
+// with the semantics of Unmarshal, e.g. *int and *int64 accept the same results. Use Scan when the kind
+// of the result has to match the target exactly.
+func (r *Result) DecodeInto(v interface{}) error {
+	return r.Unmarshal(v)
+}
+
+// scannable reports whether values of the type can be scan targets
+func scannable(t reflect.Type) bool {
+	if _, ok := scanKinds[t]; ok {
+		return true
+	}
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+
+	_, ok := scanKinds[t.Elem()]
+	return ok || t.Elem() == resultPtrType
+}
+
+// Scan stores a scalar result in the value pointed to by dest, which must be *int64, *string, *bool, *float64,
+// *[]byte or *time.Time matching the kind of the result exactly, no conversions are made. An array result is stored
+// in a slice of these types whose elements are scanned the same way, or in []*Result.
+func (r *Result) Scan(dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.Errorf("invalid scan target %T, non-nil pointer expected", dest)
+	}
+
+	return r.scan(rv.Elem())
+}
+
+func (r *Result) scan(v reflect.Value) error {
+	if r == nil {
+		return errors.Errorf("result is nil")
+	}
+
+	if kind, ok := scanKinds[v.Type()]; ok {
+		if r.kind != kind {
+			return errors.Errorf("result of kind %s cannot be scanned into %s, kind %s expected", r.kind,
+				reflect.PtrTo(v.Type()), kind)
+		}
+		if kind == KindBase64 {
+			v.SetBytes(append([]byte(nil), r.resBase64...))
+			return nil
+		}
+		v.Set(reflect.ValueOf(r.Value()))
+		return nil
+	}
+
+	if !scannable(v.Type()) {
+		return errors.Errorf("unsupported scan target %s", reflect.PtrTo(v.Type()))
+	}
+	_, scalar := scanKinds[v.Type().Elem()]
+	if r.kind != KindArray {
+		return errors.Errorf("result of kind %s cannot be scanned into %s, kind %s expected", r.kind,
+			reflect.PtrTo(v.Type()), KindArray)
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(r.resArray), len(r.resArray))
+	for i, element := range r.resArray {
+		if !scalar {
+			slice.Index(i).Set(reflect.ValueOf(element))
+			continue
+		}
+		if err := element.scan(slice.Index(i)); err != nil {
+			return errors.Wrapf(err, "element %d", i)
+		}
+	}
+	v.Set(slice)
+
+	return nil
+}
@@ -0,0 +1,368 @@
+package xmlrpc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type vmInfo struct {
+	ID       int               `xmlrpc:"id"`
+	Name     string            `xmlrpc:"name"`
+	Running  bool              `xmlrpc:"running"`
+	Memory   uint32            `xmlrpc:"memory"`
+	CPU      float64           `xmlrpc:"cpu"`
+	Created  time.Time         `xmlrpc:"created"`
+	Template []byte            `xmlrpc:"template"`
+	Disks    []int             `xmlrpc:"disks"`
+	Labels   map[string]string `xmlrpc:"labels"`
+	Extra    interface{}       `xmlrpc:"extra"`
+	Owner    *string           `xmlrpc:"owner"`
+	Ignored  string            `xmlrpc:"-"`
+}
+
+func vmResult() *Result {
+	created := time.Date(2018, 8, 1, 20, 31, 0, 0, time.UTC)
+	return &Result{kind: KindStruct, resStruct: map[string]*Result{
+		"id":       {kind: KindInt, resInt: 42},
+		"name":     {kind: KindString, resString: "pancake"},
+		"running":  {kind: KindInt, resInt: 1},
+		"memory":   {kind: KindInt, resInt: 1024},
+		"cpu":      {kind: KindInt, resInt: 2},
+		"created":  {kind: KindDateTime, resDateTime: created},
+		"template": {kind: KindBase64, resBase64: []byte("CPU=2")},
+		"disks":    {kind: KindArray, resArray: []*Result{{kind: KindInt, resInt: 1}, {kind: KindInt, resInt: 2}}},
+		"labels":   {kind: KindStruct, resStruct: map[string]*Result{"env": {kind: KindString, resString: "prod"}}},
+		"extra":    {kind: KindDouble, resDouble: 1.5},
+		"owner":    {kind: KindString, resString: "oneadmin"},
+		"-":        {kind: KindString, resString: "ignored"},
+		"unknown":  {kind: KindString, resString: "unknown"},
+	}}
+}
+
+func Test_Result_Unmarshal(t *testing.T) {
+	var vm vmInfo
+	if err := vmResult().Unmarshal(&vm); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	owner := "oneadmin"
+	expected := vmInfo{
+		ID:       42,
+		Name:     "pancake",
+		Running:  true,
+		Memory:   1024,
+		CPU:      2,
+		Created:  time.Date(2018, 8, 1, 20, 31, 0, 0, time.UTC),
+		Template: []byte("CPU=2"),
+		Disks:    []int{1, 2},
+		Labels:   map[string]string{"env": "prod"},
+		Extra:    1.5,
+		Owner:    &owner,
+	}
+	if !reflect.DeepEqual(vm, expected) {
+		t.Fatalf("Unexpected unmarshaled struct: %+v", vm)
+	}
+}
+
+func Test_Result_Unmarshal_intToBool(t *testing.T) {
+	var flags struct {
+		Enabled  bool
+		Disabled bool
+	}
+	res := &Result{kind: KindStruct, resStruct: map[string]*Result{
+		"Enabled":  {kind: KindInt, resInt: 1},
+		"Disabled": {kind: KindInt, resInt: 0},
+	}}
+	flags.Disabled = true
+	if err := res.Unmarshal(&flags); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !flags.Enabled || flags.Disabled {
+		t.Fatal("Integer isn't unmarshaled into boolean:", flags)
+	}
+
+	res.resStruct["Enabled"] = &Result{kind: KindInt, resInt: 2}
+	if err := res.Unmarshal(&flags); err == nil {
+		t.Fatal("No error when integer isn't 0 or 1.")
+	}
+}
+
+func Test_Result_Unmarshal_result(t *testing.T) {
+	var res *Result
+	if err := vmResult().Unmarshal(&res); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !reflect.DeepEqual(res, vmResult()) {
+		t.Fatal("Unexpected unmarshaled result:", res)
+	}
+
+	var value Result
+	if err := vmResult().Unmarshal(&value); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !reflect.DeepEqual(&value, vmResult()) {
+		t.Fatal("Unexpected unmarshaled result:", value)
+	}
+
+	var disks []*Result
+	original := vmResult().ResultStruct()["disks"]
+	if err := original.Unmarshal(&disks); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(disks) != 2 || disks[1].ResultInt() != 2 {
+		t.Fatal("Unexpected unmarshaled results:", disks)
+	}
+	if disks[0] == original.resArray[0] {
+		t.Fatal("Unmarshaled result isn't copied.")
+	}
+
+	var vm struct {
+		Labels *Result `xmlrpc:"labels"`
+	}
+	if err := vmResult().Unmarshal(&vm); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if vm.Labels.Kind() != KindStruct || vm.Labels.ResultStruct()["env"].ResultString() != "prod" {
+		t.Fatal("Unexpected unmarshaled struct member:", vm.Labels)
+	}
+}
+
+func Test_Result_Unmarshal_errors(t *testing.T) {
+	var vm vmInfo
+	if err := vmResult().Unmarshal(vm); err == nil {
+		t.Fatal("No error when target isn't pointer.")
+	}
+
+	var count int8
+	if err := (&Result{kind: KindInt, resInt: 300}).Unmarshal(&count); err == nil {
+		t.Fatal("No error when integer overflows target.")
+	}
+
+	var disks []int
+	res := &Result{kind: KindArray, resArray: []*Result{{kind: KindInt}, {kind: KindString, resString: "sda"}}}
+	err := res.Unmarshal(&disks)
+	if err == nil {
+		t.Fatal("No error when array element has wrong kind.")
+	}
+	if !strings.Contains(err.Error(), "element 1") {
+		t.Fatal("Error doesn't contain element index:", err)
+	}
+
+	res = vmResult()
+	res.resStruct["name"] = &Result{kind: KindInt, resInt: 42}
+	err = res.Unmarshal(&vm)
+	if err == nil {
+		t.Fatal("No error when struct member has wrong kind.")
+	}
+	if !strings.Contains(err.Error(), "member 'name'") {
+		t.Fatal("Error doesn't contain member name:", err)
+	}
+}
+
+func Test_Result_Scan(t *testing.T) {
+	created := time.Date(2018, 8, 1, 20, 31, 0, 0, time.UTC)
+	var (
+		number  int64
+		text    string
+		flag    bool
+		double  float64
+		blob    []byte
+		instant time.Time
+	)
+
+	tests := []struct {
+		result   *Result
+		dest     interface{}
+		expected interface{}
+	}{
+		{NewIntResult(42), &number, int64(42)},
+		{NewStringResult("pancake"), &text, "pancake"},
+		{NewBoolResult(true), &flag, true},
+		{NewDoubleResult(1.5), &double, 1.5},
+		{NewBase64Result([]byte("maple syrup")), &blob, []byte("maple syrup")},
+		{NewDateTimeResult(created), &instant, created},
+	}
+	for _, test := range tests {
+		if err := test.result.Scan(test.dest); err != nil {
+			t.Fatal("Error:", err)
+		}
+		if scanned := reflect.ValueOf(test.dest).Elem().Interface(); !reflect.DeepEqual(scanned, test.expected) {
+			t.Fatal("Unexpected scanned value:", scanned)
+		}
+	}
+}
+
+func Test_Result_Scan_base64Copy(t *testing.T) {
+	res := &Result{kind: KindBase64, resBase64: []byte("CPU=2")}
+	var template []byte
+	if err := res.Scan(&template); err != nil {
+		t.Fatal("Error:", err)
+	}
+	template[0] = 'X'
+	if string(res.resBase64) != "CPU=2" {
+		t.Fatal("Scanned bytes share the buffer of the result:", string(res.resBase64))
+	}
+
+	var templates [][]byte
+	if err := NewArrayResult(res).Scan(&templates); err != nil {
+		t.Fatal("Error:", err)
+	}
+	templates[0][0] = 'X'
+	if string(res.resBase64) != "CPU=2" {
+		t.Fatal("Scanned array elements share the buffer of the result:", string(res.resBase64))
+	}
+}
+
+func Test_Result_Scan_mismatch(t *testing.T) {
+	number := int64(7)
+	err := NewStringResult("42").Scan(&number)
+	if err == nil || !strings.Contains(err.Error(), "result of kind KindString cannot be scanned into *int64") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if number != 7 {
+		t.Fatal("Target is modified on mismatch.")
+	}
+
+	var text string
+	if err = NewIntResult(42).Scan(&text); err == nil {
+		t.Fatal("No error when result isn't a string.")
+	}
+	if err = NewIntResult(42).Scan(number); err == nil || !strings.Contains(err.Error(), "non-nil pointer expected") {
+		t.Fatal("Unexpected error for non-pointer target:", err)
+	}
+	var nilTarget *int64
+	if err = NewIntResult(42).Scan(nilTarget); err == nil {
+		t.Fatal("No error for nil pointer target.")
+	}
+	var small int32
+	err = NewIntResult(42).Scan(&small)
+	if err == nil || !strings.Contains(err.Error(), "unsupported scan target *int32") {
+		t.Fatal("Unexpected error for unsupported target:", err)
+	}
+	var res *Result
+	if err = res.Scan(&number); err == nil {
+		t.Fatal("No error when result is nil.")
+	}
+}
+
+func Test_Result_Scan_slice(t *testing.T) {
+	var numbers []int64
+	if err := NewArrayResult(NewIntResult(1), NewIntResult(2)).Scan(&numbers); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !reflect.DeepEqual(numbers, []int64{1, 2}) {
+		t.Fatal("Unexpected scanned integers:", numbers)
+	}
+
+	var names []string
+	if err := NewArrayResult(NewStringResult("pancake"), NewStringResult("waffle")).Scan(&names); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !reflect.DeepEqual(names, []string{"pancake", "waffle"}) {
+		t.Fatal("Unexpected scanned strings:", names)
+	}
+
+	var results []*Result
+	mixed := NewArrayResult(NewIntResult(1), NewStringResult("pancake"))
+	if err := mixed.Scan(&results); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(results) != 2 || results[1].ResultString() != "pancake" {
+		t.Fatal("Unexpected scanned results:", results)
+	}
+
+	if err := NewArrayResult().Scan(&names); err != nil || names == nil || len(names) != 0 {
+		t.Fatal("Unexpected scan of empty array:", names, err)
+	}
+}
+
+func Test_Result_Scan_sliceMismatch(t *testing.T) {
+	numbers := []int64{7}
+	err := NewArrayResult(NewIntResult(1), NewStringResult("2")).Scan(&numbers)
+	if err == nil || !strings.Contains(err.Error(), "element 1: result of kind KindString cannot be scanned into *int64") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(numbers, []int64{7}) {
+		t.Fatal("Target is modified on mismatch.")
+	}
+
+	var names []string
+	err = NewStringResult("pancake").Scan(&names)
+	if err == nil || !strings.Contains(err.Error(), "kind KindArray expected") {
+		t.Fatal("Unexpected error when result isn't an array:", err)
+	}
+	var small []int32
+	err = NewArrayResult().Scan(&small)
+	if err == nil || !strings.Contains(err.Error(), "unsupported scan target *[]int32") {
+		t.Fatal("Unexpected error for unsupported element type:", err)
+	}
+}
+
+func Test_Result_DecodeInto(t *testing.T) {
+	var vm vmInfo
+	if err := vmResult().DecodeInto(&vm); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if vm.ID != 42 || vm.Name != "pancake" || !vm.Running {
+		t.Fatal("Unexpected decoded struct:", vm)
+	}
+
+	var labels map[string]string
+	if err := vmResult().ResultStruct()["labels"].DecodeInto(&labels); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !reflect.DeepEqual(labels, map[string]string{"env": "prod"}) {
+		t.Fatal("Unexpected decoded map:", labels)
+	}
+
+	var disks []int64
+	if err := vmResult().ResultStruct()["disks"].DecodeInto(&disks); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !reflect.DeepEqual(disks, []int64{1, 2}) {
+		t.Fatal("Unexpected decoded slice:", disks)
+	}
+
+	var vms []vmInfo
+	if err := NewArrayResult(vmResult(), vmResult()).DecodeInto(&vms); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(vms) != 2 || vms[1].Name != "pancake" {
+		t.Fatal("Unexpected decoded slice of structs:", vms)
+	}
+
+	var name string
+	if err := vmResult().ResultStruct()["name"].DecodeInto(&name); err != nil || name != "pancake" {
+		t.Fatal("Unexpected decoded string:", name, err)
+	}
+}
+
+func Test_Result_DecodeInto_scalars(t *testing.T) {
+	var id int64
+	var count int
+	for _, res := range []*Result{NewIntResult(42), NewStringResult("42"), NewDoubleResult(42)} {
+		errID, errCount := res.DecodeInto(&id), res.DecodeInto(&count)
+		if errID != nil || errCount != nil || id != 42 || count != 42 {
+			t.Fatal("Int and int64 targets decode differently:", res.Kind(), id, count, errID, errCount)
+		}
+	}
+	res := NewStringResult("pancake")
+	if errID, errCount := res.DecodeInto(&id), res.DecodeInto(&count); errID == nil || errCount == nil {
+		t.Fatal("No error when string isn't integer:", errID, errCount)
+	}
+
+	var running bool
+	if err := NewIntResult(1).DecodeInto(&running); err != nil || !running {
+		t.Fatal("Unexpected decoded boolean:", running, err)
+	}
+	var memory uint32
+	if err := NewIntResult(1024).DecodeInto(&memory); err != nil || memory != 1024 {
+		t.Fatal("Unexpected decoded unsigned integer:", memory, err)
+	}
+
+	if err := NewIntResult(42).DecodeInto(id); err == nil || !strings.Contains(err.Error(), "non-nil pointer expected") {
+		t.Fatal("Unexpected error for non-pointer target:", err)
+	}
+}
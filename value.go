@@ -0,0 +1,58 @@
+package xmlrpc
+
+import "time"
+
+// Value represents an explicitly typed XML-RPC value, it is encoded as its kind without any type detection
+type Value struct {
+	kind  Kind
+	value interface{}
+}
+
+// Int returns an explicit value of integer type
+func Int(v int64) Value {
+	return Value{kind: KindInt, value: v}
+}
+
+// Str returns an explicit value of string type
+func Str(v string) Value {
+	return Value{kind: KindString, value: v}
+}
+
+// Bool returns an explicit value of boolean type
+func Bool(v bool) Value {
+	return Value{kind: KindBool, value: v}
+}
+
+// Double returns an explicit value of double type
+func Double(v float64) Value {
+	return Value{kind: KindDouble, value: v}
+}
+
+// Time returns an explicit value of date and time type
+func Time(v time.Time) Value {
+	return Value{kind: KindDateTime, value: v}
+}
+
+// Blob returns an explicit value of base64 type
+func Blob(v []byte) Value {
+	return Value{kind: KindBase64, value: v}
+}
+
+// Arr returns an explicit value of array type, elements can be both explicit and detected values
+func Arr(values ...interface{}) Value {
+	return Value{kind: KindArray, value: values}
+}
+
+// Struct returns an explicit value of struct type, members can be both explicit and detected values
+func Struct(members map[string]interface{}) Value {
+	return Value{kind: KindStruct, value: members}
+}
+
+// StructMember represents a named member of an ordered struct
+type StructMember struct {
+	Name  string
+	Value interface{}
+}
+
+// OrderedStruct represents a struct whose members are encoded in the given order
+type OrderedStruct []StructMember
@@ -0,0 +1,66 @@
+package xmlrpc
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_BuildPayload_explicitValues(t *testing.T) {
+	date := time.Date(1995, 1, 1, 6, 38, 5, 0, time.UTC)
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("get", Int(1), Str("1"), Bool(true), Double(1), Time(date), Blob([]byte("1")),
+		1, Arr(Bool(false), 0, "syrup"), Struct(map[string]interface{}{"count": Double(2)}))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := "<params>" +
+		"<param><value><int>1</int></value></param>" +
+		"<param><value><string>1</string></value></param>" +
+		"<param><value><boolean>1</boolean></value></param>" +
+		"<param><value><double>1</double></value></param>" +
+		"<param><value><dateTime.iso8601>1995-01-01T06:38:05+0000</dateTime.iso8601></value></param>" +
+		"<param><value><base64>MQ==</base64></value></param>" +
+		"<param><value><int>1</int></value></param>" +
+		"<param><value><array><data><value><boolean>0</boolean></value><value><int>0</int></value>" +
+		"<value><string>syrup</string></value></data></array></value></param>" +
+		"<param><value><struct><member><name>count</name><value><double>2</double></value></member></struct>" +
+		"</value></param></params>"
+	if !strings.Contains(string(payload), expected) {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+}
+
+func Test_BuildPayload_invalidExplicitValue(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	if _, err := client.BuildPayload("get", Value{}); err == nil {
+		t.Fatal("No error when explicit value is invalid.")
+	}
+	if _, err := client.BuildPayload("get", Arr(func() {})); err == nil {
+		t.Fatal("No error when explicit array contains invalid value.")
+	}
+}
+
+func Test_BuildPayload_orderedStruct(t *testing.T) {
+	client := NewClient(endpointCorrect, nil)
+	payload, err := client.BuildPayload("get", OrderedStruct{
+		{Name: "zucchini", Value: 3},
+		{Name: "apple", Value: "green"},
+		{Name: "mango", Value: OrderedStruct{{Name: "b", Value: true}, {Name: "a", Value: false}}},
+	})
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	expected := "<struct>" +
+		"<member><name>zucchini</name><value><int>3</int></value></member>" +
+		"<member><name>apple</name><value><string>green</string></value></member>" +
+		"<member><name>mango</name><value><struct>" +
+		"<member><name>b</name><value><boolean>1</boolean></value></member>" +
+		"<member><name>a</name><value><boolean>0</boolean></value></member>" +
+		"</struct></value></member></struct>"
+	if !strings.Contains(string(payload), expected) {
+		t.Fatal("Unexpected payload:", string(payload))
+	}
+}